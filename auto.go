@@ -0,0 +1,176 @@
+package govarint
+
+import "encoding/binary"
+import "fmt"
+import "io"
+
+// How many values the auto encoder looks at before choosing a format
+const autoSampleSize = 1024
+
+// AutoU32Encoder buffers the first values it's given, picks a format that suits them and
+// writes a header naming that format before encoding everything with it:
+//
+//	kind (one byte) | base (base128, FORGroupVarint only) | values
+//
+// Sorted samples get delta encoding, clustered ones frame of reference and anything else plain group varint.
+type AutoU32Encoder struct {
+	w      io.Writer
+	sample []uint32
+	kind   FormatKind
+	enc    U32VarintEncoder
+	closed bool
+}
+
+func NewAutoU32Encoder(w io.Writer) *AutoU32Encoder {
+	return &AutoU32Encoder{w: w, sample: make([]uint32, 0, autoSampleSize)}
+}
+
+// chooseFormat picks the format for xs, along with the base for frame of reference
+func chooseFormat(xs []uint32) (FormatKind, uint32) {
+	if len(xs) < 2 {
+		return FormatGroupVarint, 0
+	}
+	sorted := true
+	min, max := xs[0], xs[0]
+	for i, x := range xs[1:] {
+		if x < xs[i] {
+			sorted = false
+		}
+		if x < min {
+			min = x
+		}
+		if x > max {
+			max = x
+		}
+	}
+	if sorted {
+		return FormatDeltaGroupVarint, 0
+	}
+	// Clustered values are worth offsetting if that saves bytes over encoding them as they are
+	plain, offset := 0, 0
+	for _, x := range xs {
+		plain += groupVarintLen(x)
+		offset += groupVarintLen(x - min)
+	}
+	if offset < plain {
+		return FormatFORGroupVarint, min
+	}
+	return FormatGroupVarint, 0
+}
+
+// start picks the format from the sample, writes the header and encodes the sample
+func (b *AutoU32Encoder) start() (int, error) {
+	kind, base := chooseFormat(b.sample)
+	header := make([]byte, 1+binary.MaxVarintLen32)
+	header[0] = byte(kind)
+	length := 1
+	if kind == FormatFORGroupVarint {
+		length += binary.PutUvarint(header[1:], uint64(base))
+	}
+	b.kind = kind
+	n, err := b.w.Write(header[:length])
+	if err != nil {
+		return n, err
+	}
+	b.enc = NewU32GroupVarintEncoder(b.w)
+	switch kind {
+	case FormatDeltaGroupVarint:
+		b.enc = NewU32DeltaEncoder(b.enc)
+	case FormatFORGroupVarint:
+		b.enc = NewU32FOREncoder(b.enc, base)
+	}
+	for _, x := range b.sample {
+		written, err := b.enc.PutU32(x)
+		n += written
+		if err != nil {
+			return n, err
+		}
+	}
+	b.sample = nil
+	return n, nil
+}
+
+func (b *AutoU32Encoder) PutU32(x uint32) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	if b.enc != nil {
+		return b.enc.PutU32(x)
+	}
+	b.sample = append(b.sample, x)
+	if len(b.sample) == autoSampleSize {
+		return b.start()
+	}
+	return 0, nil
+}
+
+// Kind returns the chosen format, which is only known once the sample is full or the encoder is closed
+func (b *AutoU32Encoder) Kind() FormatKind {
+	return b.kind
+}
+
+// Close chooses a format from whatever was buffered if that hasn't happened yet, and flushes the final group.
+// It returns the error from writing the header, sample or final group.
+func (b *AutoU32Encoder) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	if b.enc == nil {
+		if _, err := b.start(); err != nil {
+			return err
+		}
+	}
+	return closeEncoder(b.enc)
+}
+
+///
+
+type AutoU32Decoder struct {
+	kind FormatKind
+	dec  U32VarintDecoder
+}
+
+// NewAutoU32Decoder reads the format header written by an AutoU32Encoder and decodes accordingly.
+// Headers naming a codec added with RegisterCodec are decoded with that codec.
+func NewAutoU32Decoder(r io.ByteReader) (*AutoU32Decoder, error) {
+	kind, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	b := &AutoU32Decoder{kind: FormatKind(kind)}
+	switch b.kind {
+	case FormatGroupVarint:
+		b.dec = NewU32GroupVarintDecoder(r)
+	case FormatDeltaGroupVarint:
+		b.dec = NewU32DeltaDecoder(NewU32GroupVarintDecoder(r))
+	case FormatFORGroupVarint:
+		base, err := readUvarint(r)
+		if err == io.EOF {
+			err = errUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		if base > 1<<32-1 {
+			return nil, ErrOverflow
+		}
+		b.dec = NewU32FORDecoder(NewU32GroupVarintDecoder(r), uint32(base))
+	default:
+		// Anything else has to have been registered, including Base128, which Auto never chooses itself
+		dec, err := DecoderForKind(b.kind, r)
+		if err != nil {
+			return nil, fmt.Errorf("govarint: unknown auto format %d", kind)
+		}
+		b.dec = dec
+	}
+	return b, nil
+}
+
+func (b *AutoU32Decoder) Kind() FormatKind {
+	return b.kind
+}
+
+func (b *AutoU32Decoder) GetU32() (uint32, error) {
+	return b.dec.GetU32()
+}
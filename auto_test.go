@@ -0,0 +1,80 @@
+package govarint
+
+import "bytes"
+import "io"
+import "testing"
+
+func TestAutoU32Encoder(t *testing.T) {
+	sorted := make([]uint32, 3000)
+	clustered := make([]uint32, 3000)
+	for i := range sorted {
+		sorted[i] = uint32(i * 7)
+		clustered[i] = 1<<30 + uint32(i*31%200)
+	}
+	tests := []struct {
+		name     string
+		data     []uint32
+		expected FormatKind
+	}{
+		{"sorted", sorted, FormatDeltaGroupVarint},
+		{"clustered", clustered, FormatFORGroupVarint},
+		{"random", randomU32s(3000), FormatGroupVarint},
+		{"short sorted", sorted[:10], FormatDeltaGroupVarint},
+		{"empty", nil, FormatGroupVarint},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		enc := NewAutoU32Encoder(&buf)
+		for _, x := range test.data {
+			enc.PutU32(x)
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("%s: Close: %s", test.name, err)
+		}
+		if enc.Kind() != test.expected {
+			t.Errorf("%s: encoder chose %v, expected %v", test.name, enc.Kind(), test.expected)
+		}
+		dec, err := NewAutoU32Decoder(&buf)
+		if err != nil {
+			t.Fatalf("%s: NewAutoU32Decoder: %s", test.name, err)
+		}
+		if dec.Kind() != test.expected {
+			t.Errorf("%s: decoder read %v, expected %v", test.name, dec.Kind(), test.expected)
+		}
+		for i, expected := range test.data {
+			x, err := dec.GetU32()
+			if x != expected || err != nil {
+				t.Fatalf("%s: GetU32() at %d: got x = %d, expected = %d, err = %v", test.name, i, x, expected, err)
+			}
+		}
+		if _, err := dec.GetU32(); err != io.EOF {
+			t.Errorf("%s: expected EOF after %d values, got %v", test.name, len(test.data), err)
+		}
+	}
+}
+
+func TestAutoU32EncoderCloseError(t *testing.T) {
+	// Ten values leave a partial final group in every format, which is only written on Close
+	sorted := []uint32{1, 2, 3, 5, 8, 13, 21, 34, 55, 89}
+	clustered := make([]uint32, 10)
+	for i := range clustered {
+		clustered[i] = 1<<30 + uint32(i*31%200)
+	}
+	for _, data := range [][]uint32{sorted, clustered, randomU32s(10)} {
+		var buf bytes.Buffer
+		enc := NewAutoU32Encoder(&buf)
+		for _, x := range data {
+			enc.PutU32(x)
+		}
+		enc.Close()
+		// Everything but the last byte fits
+		w := &shortWriter{limit: buf.Len() - 1}
+		enc = NewAutoU32Encoder(w)
+		for _, x := range data {
+			enc.PutU32(x)
+		}
+		if err := enc.Close(); err != io.ErrShortWrite {
+			t.Errorf("%v: Close: got err = %v, expected io.ErrShortWrite", enc.Kind(), err)
+		}
+	}
+}
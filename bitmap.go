@@ -0,0 +1,92 @@
+package govarint
+
+import "io"
+import "sort"
+
+// Containers switch from a sorted array to a bitmap once the array would take more space than the bitmap
+const arrayContainerMax = 4096
+
+// A bitmapContainer holds the low 16 bits of every member sharing the same high 16 bits
+type bitmapContainer struct {
+	array  []uint16
+	bitmap []uint64
+}
+
+func (c *bitmapContainer) add(low uint16) {
+	if c.bitmap != nil {
+		c.bitmap[low>>6] |= 1 << (low & 63)
+		return
+	}
+	// Sorted input always lands at the end, which keeps this cheap for delta encoded sets
+	i := sort.Search(len(c.array), func(i int) bool { return c.array[i] >= low })
+	if i < len(c.array) && c.array[i] == low {
+		return
+	}
+	if len(c.array) == arrayContainerMax {
+		c.bitmap = make([]uint64, 1024)
+		for _, x := range c.array {
+			c.bitmap[x>>6] |= 1 << (x & 63)
+		}
+		c.array = nil
+		c.add(low)
+		return
+	}
+	c.array = append(c.array, 0)
+	copy(c.array[i+1:], c.array[i:])
+	c.array[i] = low
+}
+
+func (c *bitmapContainer) contains(low uint16) bool {
+	if c.bitmap != nil {
+		return c.bitmap[low>>6]&(1<<(low&63)) != 0
+	}
+	i := sort.Search(len(c.array), func(i int) bool { return c.array[i] >= low })
+	return i < len(c.array) && c.array[i] == low
+}
+
+///
+
+// U32BitmapDecoder holds a set of uint32s split into containers by their high 16 bits.
+// Sparse containers are sorted arrays and dense ones are bitmaps, chosen as the set is decoded.
+type U32BitmapDecoder struct {
+	containers map[uint16]*bitmapContainer
+	count      int
+}
+
+// NewU32BitmapDecoder reads a whole delta encoded group varint set from r
+func NewU32BitmapDecoder(r io.ByteReader) (*U32BitmapDecoder, error) {
+	b := &U32BitmapDecoder{containers: make(map[uint16]*bitmapContainer)}
+	dec := NewU32DeltaDecoder(NewU32GroupVarintDecoder(r))
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			return b, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		b.add(x)
+	}
+}
+
+func (b *U32BitmapDecoder) add(x uint32) {
+	c, ok := b.containers[uint16(x>>16)]
+	if !ok {
+		c = &bitmapContainer{}
+		b.containers[uint16(x>>16)] = c
+	}
+	if !c.contains(uint16(x)) {
+		c.add(uint16(x))
+		b.count += 1
+	}
+}
+
+func (b *U32BitmapDecoder) Contains(x uint32) bool {
+	c, ok := b.containers[uint16(x>>16)]
+	return ok && c.contains(uint16(x))
+}
+
+// Len returns the number of distinct members
+func (b *U32BitmapDecoder) Len() int {
+	return b.count
+}
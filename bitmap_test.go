@@ -0,0 +1,57 @@
+package govarint
+
+import "bytes"
+import "testing"
+
+func encodeU32DeltaSet(xs []uint32) *bytes.Reader {
+	var buf bytes.Buffer
+	enc := NewU32DeltaEncoder(NewU32GroupVarintEncoder(&buf))
+	for _, x := range xs {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestU32BitmapDecoderContains(t *testing.T) {
+	set, err := NewU32BitmapDecoder(encodeU32DeltaSet([]uint32{1, 5, 9, 1000000}))
+	if err != nil {
+		t.Fatalf("NewU32BitmapDecoder: %s", err)
+	}
+	if set.Len() != 4 {
+		t.Errorf("Len(): got %d, expected 4", set.Len())
+	}
+	for _, x := range []uint32{1, 5, 9, 1000000} {
+		if !set.Contains(x) {
+			t.Errorf("Contains(%d) should be true", x)
+		}
+	}
+	for _, x := range []uint32{0, 2, 8, 10, 999999, 1000001, 1<<32 - 1} {
+		if set.Contains(x) {
+			t.Errorf("Contains(%d) should be false", x)
+		}
+	}
+}
+
+func TestU32BitmapDecoderDenseContainer(t *testing.T) {
+	// Every third value of the first 64k fills one container past the array limit
+	var xs []uint32
+	for x := uint32(0); x < 1<<16; x += 3 {
+		xs = append(xs, x)
+	}
+	set, err := NewU32BitmapDecoder(encodeU32DeltaSet(xs))
+	if err != nil {
+		t.Fatalf("NewU32BitmapDecoder: %s", err)
+	}
+	if c := set.containers[0]; c.bitmap == nil {
+		t.Errorf("Container with %d members is still an array", len(c.array))
+	}
+	if set.Len() != len(xs) {
+		t.Errorf("Len(): got %d, expected %d", set.Len(), len(xs))
+	}
+	for x := uint32(0); x < 1<<16+3; x++ {
+		if set.Contains(x) != (x%3 == 0 && x < 1<<16) {
+			t.Errorf("Contains(%d): got %t", x, set.Contains(x))
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package govarint
+
+import "encoding/binary"
+import "fmt"
+import "io"
+
+// Bit-plane encoding transposes a block of values into 32 planes, plane b holding bit b of every value,
+// and stores each plane as the lengths of its alternating runs of zeros and ones. Clustered values share
+// their high bits, so those planes are a single run, and slowly varying low bits give long runs too.
+// Noisy low bits are many short runs, where plain group varint does better.
+//
+// A block is the Base128 value count, the Base128 number of planes stored, up to the widest value,
+// and then for each plane from the lowest its run lengths, starting with a run of zeros that may be
+// empty, until they add up to the count.
+
+const (
+	defaultBitPlaneBlockSize = 64
+	// maxBitPlaneBlockSize bounds what a decoder will allocate for one block
+	maxBitPlaneBlockSize = 1 << 16
+)
+
+type BitPlaneEncoder struct {
+	w         io.Writer
+	block     []uint32
+	blockSize int
+	out       []byte
+	closed    bool
+}
+
+// NewBitPlaneEncoder encodes blockSize values at a time, 64 if it isn't positive and at most 65536
+func NewBitPlaneEncoder(w io.Writer, blockSize int) *BitPlaneEncoder {
+	if blockSize < 1 {
+		blockSize = defaultBitPlaneBlockSize
+	}
+	if blockSize > maxBitPlaneBlockSize {
+		blockSize = maxBitPlaneBlockSize
+	}
+	return &BitPlaneEncoder{w: w, block: make([]uint32, 0, blockSize), blockSize: blockSize}
+}
+
+// PutU32 adds x to the current block, returning the bytes written if that completed the block
+func (b *BitPlaneEncoder) PutU32(x uint32) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	b.block = append(b.block, x)
+	if len(b.block) < b.blockSize {
+		return 0, nil
+	}
+	return b.flush()
+}
+
+func (b *BitPlaneEncoder) flush() (int, error) {
+	var all uint32
+	for _, x := range b.block {
+		all |= x
+	}
+	planes := 0
+	for all>>uint(planes) != 0 {
+		planes += 1
+	}
+	out := binary.AppendUvarint(b.out[:0], uint64(len(b.block)))
+	out = binary.AppendUvarint(out, uint64(planes))
+	for plane := uint(0); plane < uint(planes); plane++ {
+		bit, run := uint32(0), 0
+		for _, x := range b.block {
+			if (x>>plane)&1 != bit {
+				out = binary.AppendUvarint(out, uint64(run))
+				bit, run = bit^1, 0
+			}
+			run += 1
+		}
+		out = binary.AppendUvarint(out, uint64(run))
+	}
+	b.out = out
+	b.block = b.block[:0]
+	return b.w.Write(out)
+}
+
+// Close writes the final, possibly short, block, returning any error from doing so
+func (b *BitPlaneEncoder) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	if len(b.block) == 0 {
+		return nil
+	}
+	_, err := b.flush()
+	return err
+}
+
+///
+
+type BitPlaneDecoder struct {
+	r     io.ByteReader
+	block []uint32
+	pos   int
+}
+
+func NewBitPlaneDecoder(r io.ByteReader) *BitPlaneDecoder {
+	return &BitPlaneDecoder{r: r}
+}
+
+func (b *BitPlaneDecoder) readBlock() error {
+	count, err := readUvarint(b.r)
+	if err != nil {
+		return err
+	}
+	if count == 0 || count > maxBitPlaneBlockSize {
+		return fmt.Errorf("govarint: bit-plane block of %d values", count)
+	}
+	planes, err := readUvarint(b.r)
+	if err == nil && planes > 32 {
+		err = fmt.Errorf("govarint: bit-plane block with %d planes", planes)
+	}
+	n := int(count)
+	if cap(b.block) < n {
+		b.block = make([]uint32, n)
+	}
+	b.block = b.block[:n]
+	for i := range b.block {
+		b.block[i] = 0
+	}
+	for plane := uint(0); plane < uint(planes) && err == nil; plane++ {
+		bit := uint32(0)
+		for i := 0; i < n; bit ^= 1 {
+			var run uint64
+			run, err = readUvarint(b.r)
+			if err != nil {
+				break
+			}
+			if run > uint64(n-i) {
+				err = fmt.Errorf("govarint: bit-plane run of %d overruns a block of %d values", run, n)
+				break
+			}
+			for end := i + int(run); i < end; i++ {
+				b.block[i] |= bit << plane
+			}
+		}
+	}
+	if err == io.EOF {
+		err = errUnexpectedEOF
+	}
+	if err != nil {
+		b.block = b.block[:0]
+		return err
+	}
+	b.pos = 0
+	return nil
+}
+
+func (b *BitPlaneDecoder) GetU32() (uint32, error) {
+	if b.pos == len(b.block) {
+		if err := b.readBlock(); err != nil {
+			return 0, err
+		}
+	}
+	b.pos += 1
+	return b.block[b.pos-1], nil
+}
@@ -0,0 +1,48 @@
+package govarint
+
+import "bytes"
+import "io"
+import "testing"
+
+func TestBitPlaneRoundTrip(t *testing.T) {
+	// Two full blocks of slowly rising values of similar magnitude and a short final block
+	var data []uint32
+	for i := 0; i < 150; i++ {
+		data = append(data, 100000+uint32(i/8))
+	}
+	var buf bytes.Buffer
+	enc := NewBitPlaneEncoder(&buf, 64)
+	for _, x := range data {
+		if _, err := enc.PutU32(x); err != nil {
+			t.Fatalf("PutU32: %s", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	if plain := GroupVarintEncodedLen(data); buf.Len()*4 > plain {
+		t.Errorf("Bit-plane encoding took %d bytes, expected well under the %d of group varint", buf.Len(), plain)
+	}
+	dec := NewBitPlaneDecoder(&buf)
+	for i, expected := range data {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Errorf("Got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after %d values, got %v", len(data), err)
+	}
+	// Full range values, zeros and a block size of one
+	buf.Reset()
+	enc = NewBitPlaneEncoder(&buf, 1)
+	for _, x := range testU32 {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	dec = NewBitPlaneDecoder(&buf)
+	for i, expected := range testU32 {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Errorf("Got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+		}
+	}
+}
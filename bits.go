@@ -0,0 +1,41 @@
+package govarint
+
+import "io"
+
+// BitReader reads from a bit packed stream, returning the next n bits (at most 64)
+// as the low bits of the result. It returns io.EOF if fewer than n bits are left.
+type BitReader interface {
+	ReadBits(n uint) (uint64, error)
+}
+
+// BitU32Decoder reads Base128 varints that aren't aligned to byte boundaries,
+// taking each varint byte as the next eight bits of a BitReader
+type BitU32Decoder struct {
+	br BitReader
+}
+
+func NewBitU32Decoder(br BitReader) *BitU32Decoder {
+	return &BitU32Decoder{br: br}
+}
+
+func (b *BitU32Decoder) GetU32() (uint32, error) {
+	x := uint32(0)
+	for i := uint(0); i < 5; i++ {
+		y, err := b.br.ReadBits(8)
+		if err != nil {
+			if i > 0 && err == io.EOF {
+				err = errUnexpectedEOF
+			}
+			return 0, err
+		}
+		// The fifth byte only has room for the top four bits
+		if i == 4 && y > 0x0f {
+			return 0, ErrOverflow
+		}
+		x |= uint32(y&0x7f) << (7 * i)
+		if y < 0x80 {
+			return x, nil
+		}
+	}
+	return 0, ErrOverflow
+}
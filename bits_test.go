@@ -0,0 +1,57 @@
+package govarint
+
+import "bytes"
+import "io"
+import "testing"
+
+// memoryBits reads bits most significant first out of a byte slice
+type memoryBits struct {
+	data []byte
+	pos  uint
+}
+
+func (m *memoryBits) ReadBits(n uint) (uint64, error) {
+	if m.pos+n > uint(len(m.data))*8 {
+		return 0, io.EOF
+	}
+	x := uint64(0)
+	for i := uint(0); i < n; i++ {
+		bit := m.data[m.pos/8] >> (7 - m.pos%8) & 1
+		x = x<<1 | uint64(bit)
+		m.pos += 1
+	}
+	return x, nil
+}
+
+func TestBitU32Decoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewU32Base128Encoder(&buf)
+	for _, x := range fiveU32 {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	// Shift the varints along by a 3 bit header of 0b101, so none of them start on a byte boundary
+	header := uint(0x5)
+	packed := make([]byte, buf.Len()+1)
+	carry := byte(header << 5)
+	for i, y := range buf.Bytes() {
+		packed[i] = carry | y>>3
+		carry = y << 5
+	}
+	packed[buf.Len()] = carry
+	bits := &memoryBits{data: packed}
+	if h, err := bits.ReadBits(3); h != uint64(header) || err != nil {
+		t.Fatalf("Reading the header: got %b, err = %v", h, err)
+	}
+	dec := NewBitU32Decoder(bits)
+	for i, expected := range fiveU32 {
+		x, err := dec.GetU32()
+		if x != expected || err != nil {
+			t.Errorf("GetU32() at %d: got x = %d, expected = %d, err = %v", i, x, expected, err)
+		}
+	}
+	// Only the five padding bits are left, which isn't enough for another varint
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after %d values, got %v", len(fiveU32), err)
+	}
+}
@@ -0,0 +1,181 @@
+package govarint
+
+import "bytes"
+import "encoding/binary"
+import "errors"
+import "fmt"
+import "io"
+
+const blockSize = 128
+
+// U32BlockEncoder splits values into blocks of 128. Each block's first value (its base) goes into
+// a block directory and the remaining 127 are written as group varint deltas from their predecessor.
+// On Close the directory is appended so any single block can be decoded without touching the rest:
+//
+//	block 0 | block 1 | ... | count, blocks, (base, len) per block (base128) | directory length (uint32, little endian)
+//
+// Deltas wrap around, so any sequence round-trips, but only ascending ones compress well.
+type U32BlockEncoder struct {
+	w      io.Writer
+	block  []uint32
+	count  int
+	bases  []uint32
+	sizes  []int
+	buf    bytes.Buffer
+	closed bool
+}
+
+func NewU32BlockEncoder(w io.Writer) *U32BlockEncoder {
+	return &U32BlockEncoder{w: w, block: make([]uint32, 0, blockSize)}
+}
+
+func (b *U32BlockEncoder) PutU32(x uint32) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	b.block = append(b.block, x)
+	b.count += 1
+	if len(b.block) == blockSize {
+		return b.flushBlock()
+	}
+	return 0, nil
+}
+
+func (b *U32BlockEncoder) flushBlock() (int, error) {
+	if len(b.block) == 0 {
+		return 0, nil
+	}
+	b.buf.Reset()
+	enc := NewU32GroupVarintEncoder(&b.buf)
+	for i := 1; i < len(b.block); i++ {
+		enc.PutU32(b.block[i] - b.block[i-1])
+	}
+	enc.Close()
+	b.bases = append(b.bases, b.block[0])
+	b.sizes = append(b.sizes, b.buf.Len())
+	b.block = b.block[:0]
+	return b.w.Write(b.buf.Bytes())
+}
+
+// Close writes any short final block followed by the block directory
+func (b *U32BlockEncoder) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	if _, err := b.flushBlock(); err != nil {
+		return err
+	}
+	var dir []byte
+	tmp := make([]byte, binary.MaxVarintLen64)
+	dir = append(dir, tmp[:binary.PutUvarint(tmp, uint64(b.count))]...)
+	dir = append(dir, tmp[:binary.PutUvarint(tmp, uint64(len(b.bases)))]...)
+	for i, base := range b.bases {
+		dir = append(dir, tmp[:binary.PutUvarint(tmp, uint64(base))]...)
+		dir = append(dir, tmp[:binary.PutUvarint(tmp, uint64(b.sizes[i]))]...)
+	}
+	binary.LittleEndian.PutUint32(tmp, uint32(len(dir)))
+	dir = append(dir, tmp[:4]...)
+	_, err := b.w.Write(dir)
+	return err
+}
+
+///
+
+type U32BlockDecoder struct {
+	data    []byte
+	count   int
+	bases   []uint32
+	offsets []int
+}
+
+func NewU32BlockDecoder(data []byte) (*U32BlockDecoder, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("govarint: block data too short for its directory length: %w", ErrTruncated)
+	}
+	dirLen := int(binary.LittleEndian.Uint32(data[len(data)-4:]))
+	if dirLen > len(data)-4 {
+		return nil, fmt.Errorf("govarint: block directory length %d exceeds data size: %w", dirLen, ErrTruncated)
+	}
+	blocks := data[:len(data)-4-dirLen]
+	dir := data[len(blocks) : len(data)-4]
+	var fields [2]uint64
+	for i := range fields {
+		v, n := binary.Uvarint(dir)
+		if n <= 0 {
+			return nil, errors.New("govarint: corrupt block directory")
+		}
+		fields[i] = v
+		dir = dir[n:]
+	}
+	count, numBlocks := fields[0], fields[1]
+	if numBlocks != (count+blockSize-1)/blockSize {
+		return nil, fmt.Errorf("govarint: %d blocks can't hold %d values", numBlocks, count)
+	}
+	b := &U32BlockDecoder{data: blocks, count: int(count), offsets: []int{0}}
+	for i := uint64(0); i < numBlocks; i++ {
+		base, n := binary.Uvarint(dir)
+		if n <= 0 || base > 1<<32-1 {
+			return nil, errors.New("govarint: corrupt block directory")
+		}
+		dir = dir[n:]
+		size, n := binary.Uvarint(dir)
+		if n <= 0 {
+			return nil, errors.New("govarint: corrupt block directory")
+		}
+		dir = dir[n:]
+		end := b.offsets[len(b.offsets)-1] + int(size)
+		if size > uint64(len(blocks)) || end > len(blocks) {
+			return nil, fmt.Errorf("govarint: block %d runs past the end of the data: %w", i, ErrTruncated)
+		}
+		b.bases = append(b.bases, uint32(base))
+		b.offsets = append(b.offsets, end)
+	}
+	return b, nil
+}
+
+// Len returns the total number of values across all blocks
+func (b *U32BlockDecoder) Len() int {
+	return b.count
+}
+
+func (b *U32BlockDecoder) NumBlocks() int {
+	return len(b.bases)
+}
+
+// Block decodes the i-th block of (up to) 128 values
+func (b *U32BlockDecoder) Block(i int) ([]uint32, error) {
+	if i < 0 || i >= b.NumBlocks() {
+		return nil, fmt.Errorf("govarint: block index %d out of range [0, %d)", i, b.NumBlocks())
+	}
+	length := blockSize
+	if i == b.NumBlocks()-1 {
+		length = b.count - i*blockSize
+	}
+	xs := make([]uint32, length)
+	xs[0] = b.bases[i]
+	dec := NewU32GroupVarintSliceDecoder(b.data[b.offsets[i]:b.offsets[i+1]])
+	for j := 1; j < length; j++ {
+		delta, err := dec.GetU32()
+		if err == io.EOF {
+			return nil, errUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		xs[j] = xs[j-1] + delta
+	}
+	return xs, nil
+}
+
+// Get returns the value at index, decoding only the block that holds it
+func (b *U32BlockDecoder) Get(index int) (uint32, error) {
+	if index < 0 || index >= b.count {
+		return 0, fmt.Errorf("govarint: value index %d out of range [0, %d)", index, b.count)
+	}
+	xs, err := b.Block(index / blockSize)
+	if err != nil {
+		return 0, err
+	}
+	return xs[index%blockSize], nil
+}
@@ -0,0 +1,52 @@
+package govarint
+
+import "bytes"
+import "testing"
+
+func TestU32BlockEncoderRoundTrip(t *testing.T) {
+	// Two full blocks of 128 and a short one of 44
+	data := make([]uint32, 300)
+	for i := range data {
+		data[i] = uint32(1000 + i*i)
+	}
+	var buf bytes.Buffer
+	enc := NewU32BlockEncoder(&buf)
+	for _, x := range data {
+		enc.PutU32(x)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	dec, err := NewU32BlockDecoder(buf.Bytes())
+	if err != nil {
+		t.Fatalf("NewU32BlockDecoder: %s", err)
+	}
+	if dec.Len() != len(data) || dec.NumBlocks() != 3 {
+		t.Fatalf("Got %d values in %d blocks, expected %d values in 3 blocks", dec.Len(), dec.NumBlocks(), len(data))
+	}
+	var decoded []uint32
+	for i := 0; i < dec.NumBlocks(); i++ {
+		xs, err := dec.Block(i)
+		if err != nil {
+			t.Fatalf("Block(%d): %s", i, err)
+		}
+		decoded = append(decoded, xs...)
+	}
+	if len(decoded) != len(data) {
+		t.Fatalf("%d integers were decoded when %d were encoded", len(decoded), len(data))
+	}
+	for i, expected := range data {
+		if decoded[i] != expected {
+			t.Errorf("Got x = %d, expected = %d at %d", decoded[i], expected, i)
+		}
+	}
+	for _, i := range []int{0, 127, 128, 255, 256, 299} {
+		x, err := dec.Get(i)
+		if x != data[i] || err != nil {
+			t.Errorf("Get(%d): got x = %d, expected = %d, err = %v", i, x, data[i], err)
+		}
+	}
+	if _, err := dec.Get(len(data)); err == nil {
+		t.Errorf("Get(%d) should fail", len(data))
+	}
+}
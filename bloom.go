@@ -0,0 +1,96 @@
+package govarint
+
+import "io"
+import "math"
+
+// A Bloom filter is written as one byte holding the number of hash functions k and then the bit array,
+// a whole number of bytes. Bit i is bit i%8 of byte i/8 of the array. The k positions for a value come
+// from two halves of a 64 bit mix of it, by double hashing.
+
+type U32BloomEncoder struct {
+	group *U32GroupVarintEncoder
+	enc   *U32DeltaEncoder
+	w     io.Writer
+	bits  []byte
+	k     int
+}
+
+// NewU32BloomEncoder writes increasing values to data as delta encoded group varint, and on Close writes
+// a Bloom filter of them to bloom, sized for n values with a false positive rate of fpRate.
+// An fpRate outside (0, 1) means 1%.
+func NewU32BloomEncoder(data io.Writer, bloom io.Writer, n int, fpRate float64) *U32BloomEncoder {
+	if n < 1 {
+		n = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+	// The standard optimum: m = -n ln p / (ln 2)^2 bits and k = m/n ln 2 hash functions
+	m := math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	k := int(math.Round(m / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	if k > 255 {
+		k = 255
+	}
+	group := NewU32GroupVarintEncoder(data)
+	return &U32BloomEncoder{group: group, enc: NewU32DeltaEncoder(group), w: bloom, bits: make([]byte, (int(m)+7)/8), k: k}
+}
+
+// bloomHashes returns the two hashes that positions for x are derived from, the second always odd
+func bloomHashes(x uint32) (uint64, uint64) {
+	// The splitmix64 finalizer
+	h := uint64(x) + 0x9e3779b97f4a7c15
+	h = (h ^ h>>30) * 0xbf58476d1ce4e5b9
+	h = (h ^ h>>27) * 0x94d049bb133111eb
+	h ^= h >> 31
+	return h & 0xffffffff, h>>32 | 1
+}
+
+func (b *U32BloomEncoder) PutU32(x uint32) (int, error) {
+	n, err := b.enc.PutU32(x)
+	if err != nil {
+		return n, err
+	}
+	m := uint64(len(b.bits)) * 8
+	h1, h2 := bloomHashes(x)
+	for i := uint64(0); i < uint64(b.k); i++ {
+		bit := (h1 + i*h2) % m
+		b.bits[bit/8] |= 1 << (bit % 8)
+	}
+	return n, nil
+}
+
+// Close writes any partial group to data and then the filter to bloom, returning any error from doing so
+func (b *U32BloomEncoder) Close() error {
+	if b.group.closed {
+		return nil
+	}
+	if _, err := b.group.finish(); err != nil {
+		return err
+	}
+	if _, err := b.w.Write([]byte{byte(b.k)}); err != nil {
+		return err
+	}
+	_, err := b.w.Write(b.bits)
+	return err
+}
+
+// BloomContains reports whether x may be in the set whose filter is bloom. False means x is definitely absent;
+// true means it's present or a false positive. A malformed filter can't rule anything out, so gives true.
+func BloomContains(bloom []byte, x uint32) bool {
+	if len(bloom) < 2 || bloom[0] == 0 {
+		return true
+	}
+	k, bits := uint64(bloom[0]), bloom[1:]
+	m := uint64(len(bits)) * 8
+	h1, h2 := bloomHashes(x)
+	for i := uint64(0); i < k; i++ {
+		bit := (h1 + i*h2) % m
+		if bits[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package govarint
+
+import "bytes"
+import "testing"
+
+func TestU32BloomEncoder(t *testing.T) {
+	var data, bloom bytes.Buffer
+	enc := NewU32BloomEncoder(&data, &bloom, 1000, 0.01)
+	var set []uint32
+	for i := uint32(0); i < 1000; i++ {
+		set = append(set, i*10)
+		if _, err := enc.PutU32(i * 10); err != nil {
+			t.Fatalf("PutU32: %s", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	decoded, err := DecodeSortedU32(&data, false)
+	if err != nil || len(decoded) != len(set) {
+		t.Fatalf("Decoding the data stream: got %d values, err = %v, expected %d", len(decoded), err, len(set))
+	}
+	for i, x := range set {
+		if decoded[i] != x {
+			t.Errorf("Got x = %d, expected = %d at %d", decoded[i], x, i)
+		}
+		if !BloomContains(bloom.Bytes(), x) {
+			t.Errorf("BloomContains(%d) is false for a member of the set", x)
+		}
+	}
+	// Values between the members are absent, and only about 1% should look present
+	falsePositives := 0
+	for i := uint32(0); i < 10000; i++ {
+		if BloomContains(bloom.Bytes(), i*10+5) {
+			falsePositives += 1
+		}
+	}
+	if falsePositives > 200 {
+		t.Errorf("%d false positives in 10000 lookups, expected about 100", falsePositives)
+	}
+	if !BloomContains(nil, 1) {
+		t.Errorf("A malformed filter should not rule out any value")
+	}
+}
@@ -0,0 +1,81 @@
+package govarint
+
+import "bufio"
+import "io"
+
+const (
+	// minEncoderBufferSize is where a buffered encoder's buffer starts, the bufio default
+	minEncoderBufferSize = 4096
+	// bufferedGroupsPerWrite is how many groups of the observed average size the buffer aims to hold
+	bufferedGroupsPerWrite = 512
+)
+
+// BufferedU32GroupVarintEncoder writes group varint through a bufio.Writer whose size follows the data.
+// It starts at 4KB and doubles, up to maxBufferSize, whenever the average group seen so far means the
+// buffer holds fewer than 512 groups. Wide values then reach the underlying writer in fewer, larger writes,
+// while narrow ones never pay for a large buffer.
+type BufferedU32GroupVarintEncoder struct {
+	w       io.Writer
+	bw      *bufio.Writer
+	enc     *U32GroupVarintEncoder
+	size    int
+	maxSize int
+	groups  int64
+	bytes   int64
+}
+
+func NewBufferedU32GroupVarintEncoder(w io.Writer, maxBufferSize int) *BufferedU32GroupVarintEncoder {
+	size := minEncoderBufferSize
+	if maxBufferSize < size {
+		size = maxBufferSize
+	}
+	bw := bufio.NewWriterSize(w, size)
+	// bufio may round a tiny size up, so report what it actually chose
+	size = bw.Size()
+	return &BufferedU32GroupVarintEncoder{w: w, bw: bw, enc: NewU32GroupVarintEncoder(bw), size: size, maxSize: maxBufferSize}
+}
+
+func (b *BufferedU32GroupVarintEncoder) PutU32(x uint32) (int, error) {
+	n, err := b.enc.PutU32(x)
+	if err != nil || n == 0 {
+		return n, err
+	}
+	b.groups += 1
+	b.bytes += int64(n)
+	return n, b.grow()
+}
+
+// grow moves to a larger buffer if the groups seen so far call for one
+func (b *BufferedU32GroupVarintEncoder) grow() error {
+	want := b.bytes / b.groups * bufferedGroupsPerWrite
+	if int64(b.size) >= want || b.size >= b.maxSize {
+		return nil
+	}
+	size := b.size * 2
+	if size > b.maxSize {
+		size = b.maxSize
+	}
+	if err := b.bw.Flush(); err != nil {
+		return err
+	}
+	b.bw = bufio.NewWriterSize(b.w, size)
+	b.enc.w = b.bw
+	b.size = size
+	return nil
+}
+
+// BufferSize returns the size of the buffer currently in use
+func (b *BufferedU32GroupVarintEncoder) BufferSize() int {
+	return b.size
+}
+
+// Close writes out any partial group and flushes the buffer, returning any error from doing so
+func (b *BufferedU32GroupVarintEncoder) Close() error {
+	if b.enc.closed {
+		return nil
+	}
+	if _, err := b.enc.finish(); err != nil {
+		return err
+	}
+	return b.bw.Flush()
+}
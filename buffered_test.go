@@ -0,0 +1,50 @@
+package govarint
+
+import "bytes"
+import "testing"
+
+func TestBufferedU32GroupVarintEncoder(t *testing.T) {
+	const maxSize = 12 << 10
+	var buf bytes.Buffer
+	enc := NewBufferedU32GroupVarintEncoder(&buf, maxSize)
+	if enc.BufferSize() != 4096 {
+		t.Fatalf("Initial buffer size is %d, expected 4096", enc.BufferSize())
+	}
+	// Four byte values make 17 byte groups, so 512 of them want 8704 bytes and the buffer doubles
+	// to 8192, then would double again to 16384 but is capped
+	var values []uint32
+	sizes := map[int]bool{}
+	for i := uint32(0); i < 10000; i++ {
+		values = append(values, 1<<31+i)
+		enc.PutU32(1<<31 + i)
+		sizes[enc.BufferSize()] = true
+	}
+	if !sizes[8192] {
+		t.Errorf("Buffer never grew to 8192, went through sizes %v", sizes)
+	}
+	if enc.BufferSize() != maxSize {
+		t.Errorf("Buffer size is %d, expected it to be capped at %d", enc.BufferSize(), maxSize)
+	}
+	values = append(values, 7)
+	enc.PutU32(7)
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	decoded := decodeU32GroupVarint(t, buf.Bytes())
+	if len(decoded) != len(values) {
+		t.Fatalf("%d integers were decoded when %d were encoded", len(decoded), len(values))
+	}
+	for i := range values {
+		if decoded[i] != values[i] {
+			t.Fatalf("Got x = %d, expected = %d at %d", decoded[i], values[i], i)
+		}
+	}
+	// Narrow values never need more than the initial buffer
+	enc = NewBufferedU32GroupVarintEncoder(&buf, maxSize)
+	for i := 0; i < 10000; i++ {
+		enc.PutU32(1)
+	}
+	if enc.BufferSize() != 4096 {
+		t.Errorf("Buffer size is %d for single byte values, expected 4096", enc.BufferSize())
+	}
+}
@@ -0,0 +1,196 @@
+package govarint
+
+import "encoding/binary"
+import "fmt"
+import "hash/fnv"
+import "io"
+
+// Helpers that work directly on buffers of encoded group varint
+
+// groupVarintOffsets walks the size bytes of data and returns the offset at which each group starts.
+// Only the final group can be partial, in which case it runs to the end of data.
+func groupVarintOffsets(data []byte) []int {
+	var offsets []int
+	for off := 0; off < len(data); off += groupVarintGroupLen(data[off]) {
+		offsets = append(offsets, off)
+	}
+	return offsets
+}
+
+// SplitU32GroupVarint divides data into parts contiguous chunks that each end on a group boundary,
+// so every chunk can be decoded on its own. Chunks get an equal number of groups and the last one
+// also takes the remainder, including any partial final group. If there are fewer groups than parts,
+// the leading chunks are empty.
+func SplitU32GroupVarint(data []byte, parts int) ([][]byte, error) {
+	if parts < 1 {
+		return nil, fmt.Errorf("govarint: can't split into %d parts", parts)
+	}
+	offsets := groupVarintOffsets(data)
+	perPart := len(offsets) / parts
+	chunks := make([][]byte, parts)
+	start := 0
+	for i := range chunks {
+		end := len(data)
+		if i < parts-1 && (i+1)*perPart < len(offsets) {
+			end = offsets[(i+1)*perPart]
+		}
+		chunks[i] = data[start:end]
+		start = end
+	}
+	return chunks, nil
+}
+
+// OffsetOfValue locates value n of the group varint buffer data from the size bytes alone, returning the offset
+// of the group holding it and where the value's own bytes start relative to that group, so at least one.
+// It fails if data holds n values or fewer, or is cut off partway through a value up to value n.
+func OffsetOfValue(data []byte, n int) (groupOff int64, valueOff int, err error) {
+	if n < 0 {
+		return 0, 0, fmt.Errorf("govarint: value index %d out of range", n)
+	}
+	index := n
+	off := 0
+	for off < len(data) {
+		sizeByte := data[off]
+		if end := off + groupVarintGroupLen(sizeByte); n >= 4 && end <= len(data) {
+			n -= 4
+			off = end
+			continue
+		}
+		valueOff = 1
+		for i, length := range &controlTable[sizeByte] {
+			if off+valueOff == len(data) {
+				// The partial final group has run out before value n
+				break
+			}
+			if off+valueOff+int(length) > len(data) {
+				return 0, 0, fmt.Errorf("govarint: group at offset %d is cut off: %w", off, ErrTruncated)
+			}
+			if i == n {
+				return int64(off), valueOff, nil
+			}
+			valueOff += int(length)
+		}
+		break
+	}
+	return 0, 0, fmt.Errorf("govarint: value index %d out of range", index)
+}
+
+// VerifyU32GroupVarintCount decodes data and checks it holds exactly expected values, failing with
+// ErrCountMismatch if not. A known count pins down the size of the final partial group, so this
+// catches data cut off at a group boundary or running on past the values it should hold.
+func VerifyU32GroupVarintCount(data []byte, expected int) error {
+	dec := NewU32GroupVarintSliceDecoder(data)
+	actual := 0
+	for {
+		_, err := dec.GetU32()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		actual += 1
+	}
+	if actual != expected {
+		return fmt.Errorf("govarint: data holds %d values, expected %d: %w", actual, expected, ErrCountMismatch)
+	}
+	return nil
+}
+
+// TruncateU32GroupVarint returns a copy of data holding only its first n values. If n doesn't land on a
+// group boundary, the group holding value n-1 becomes a partial final group, with its size byte rewritten
+// to drop the values after it. It fails if data holds fewer than n values.
+func TruncateU32GroupVarint(data []byte, n int) ([]byte, error) {
+	if n == 0 {
+		return []byte{}, nil
+	}
+	groupOff, valueOff, err := OffsetOfValue(data, n-1)
+	if err != nil {
+		return nil, err
+	}
+	sizeByte := data[groupOff]
+	kept := (n-1)%4 + 1
+	end := int(groupOff) + valueOff + int(controlTable[sizeByte][kept-1])
+	out := append([]byte{}, data[:end]...)
+	// Zero the sizes of the dropped values, as the encoder does for a partial group
+	out[groupOff] = sizeByte &^ (0xff >> uint(2*kept))
+	return out, nil
+}
+
+// AnalyzeU32GroupVarint walks the size bytes of the group varint buffer data, counting in counts[i] the values
+// that take i+1 bytes, along with the number of groups including a partial final one. The totals match the
+// EncoderMetrics of the encoder that wrote data. It fails if data is cut off partway through a value.
+func AnalyzeU32GroupVarint(data []byte) (counts [4]uint64, groups uint64, err error) {
+	for off := 0; off < len(data); {
+		sizeByte := data[off]
+		groups += 1
+		end := off + groupVarintGroupLen(sizeByte)
+		if end <= len(data) {
+			for _, length := range &controlTable[sizeByte] {
+				counts[length-1] += 1
+			}
+			off = end
+			continue
+		}
+		// A partial final group holds as many values as fit before the end of data
+		off += 1
+		for _, length := range &controlTable[sizeByte] {
+			if off == len(data) {
+				break
+			}
+			if off+int(length) > len(data) {
+				return counts, groups, fmt.Errorf("govarint: value at offset %d is cut off: %w", off, ErrTruncated)
+			}
+			counts[length-1] += 1
+			off += int(length)
+		}
+	}
+	return counts, groups, nil
+}
+
+// EqualU32GroupVarint reports whether the group varint buffers a and b decode to the same values,
+// stopping at the first difference. Buffers can differ byte for byte and still be equal, when a value
+// takes more bytes than it needs. A decode error from either buffer before a difference is returned.
+func EqualU32GroupVarint(a, b []byte) (bool, error) {
+	da := NewU32GroupVarintSliceDecoder(a)
+	db := NewU32GroupVarintSliceDecoder(b)
+	for {
+		x, errA := da.GetU32()
+		y, errB := db.GetU32()
+		if errA != nil && errA != io.EOF {
+			return false, errA
+		}
+		if errB != nil && errB != io.EOF {
+			return false, errB
+		}
+		if errA == io.EOF || errB == io.EOF {
+			// Equal only if both ran out together
+			return errA == errB, nil
+		}
+		if x != y {
+			return false, nil
+		}
+	}
+}
+
+// HashU32GroupVarint returns the 64 bit FNV-1a hash of the values data decodes to, each as four little-endian
+// bytes, so two encodings of the same values hash the same however many bytes they give each value.
+// If data doesn't decode cleanly, the values before the error are hashed along with one extra byte,
+// which keeps it from hashing the same as the valid buffer holding just those values.
+func HashU32GroupVarint(data []byte) uint64 {
+	h := fnv.New64a()
+	dec := NewU32GroupVarintSliceDecoder(data)
+	var tmp [4]byte
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			return h.Sum64()
+		}
+		if err != nil {
+			h.Write([]byte{0xff})
+			return h.Sum64()
+		}
+		binary.LittleEndian.PutUint32(tmp[:], x)
+		h.Write(tmp[:])
+	}
+}
@@ -0,0 +1,479 @@
+package govarint
+
+import "bytes"
+import "errors"
+import "io"
+import "math/rand"
+import "testing"
+
+func encodeU32GroupVarint(xs []uint32) []byte {
+	var buf bytes.Buffer
+	enc := NewU32GroupVarintEncoder(&buf)
+	for _, x := range xs {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	return buf.Bytes()
+}
+
+func decodeU32GroupVarint(t *testing.T, data []byte) []uint32 {
+	var xs []uint32
+	dec := NewU32GroupVarintSliceDecoder(data)
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			return xs
+		}
+		if err != nil {
+			t.Fatalf("GetU32() after %d values: %s", len(xs), err)
+		}
+		xs = append(xs, x)
+	}
+}
+
+func randomU32s(n int) []uint32 {
+	r := rand.New(rand.NewSource(42))
+	xs := make([]uint32, n)
+	for i := range xs {
+		// Spread the values over every byte length
+		xs[i] = r.Uint32() >> (8 * uint(r.Intn(4)))
+	}
+	return xs
+}
+
+func TestSplitU32GroupVarint(t *testing.T) {
+	data := randomU32s(1000)
+	encoded := encodeU32GroupVarint(data)
+	chunks, err := SplitU32GroupVarint(encoded, 4)
+	if err != nil {
+		t.Fatalf("SplitU32GroupVarint: %s", err)
+	}
+	if len(chunks) != 4 {
+		t.Fatalf("Got %d chunks, expected 4", len(chunks))
+	}
+	var decoded []uint32
+	for i, chunk := range chunks {
+		xs := decodeU32GroupVarint(t, chunk)
+		// 250 groups split four ways means 62 groups for the first three chunks and 64 for the last
+		if i < 3 && len(xs) != 62*4 {
+			t.Errorf("Chunk %d decoded %d values, expected %d", i, len(xs), 62*4)
+		}
+		decoded = append(decoded, xs...)
+	}
+	if len(decoded) != len(data) {
+		t.Fatalf("%d integers were decoded when %d were encoded", len(decoded), len(data))
+	}
+	for i, expected := range data {
+		if decoded[i] != expected {
+			t.Errorf("Got x = %d, expected = %d at %d", decoded[i], expected, i)
+		}
+	}
+	if _, err := SplitU32GroupVarint(encoded, 0); err == nil {
+		t.Errorf("SplitU32GroupVarint with 0 parts should fail")
+	}
+}
+
+// buildGroupIndex returns the byte offset of every group that starts with a multiple of stride
+func buildGroupIndex(data []byte, stride int) []int64 {
+	var idx []int64
+	for i, off := range groupVarintOffsets(data) {
+		if (i*4)%stride == 0 {
+			idx = append(idx, int64(off))
+		}
+	}
+	return idx
+}
+
+func TestU32GroupVarintSliceDecoderGetAt(t *testing.T) {
+	data := randomU32s(1000)
+	encoded := encodeU32GroupVarint(data)
+	idx := buildGroupIndex(encoded, 64)
+	dec := NewU32GroupVarintSliceDecoder(encoded)
+	for _, i := range []int{0, 1, 63, 64, 65, 500, 511, 512, 960, 998, 999, 3} {
+		x, err := dec.GetAt(i, idx, 64)
+		if x != data[i] || err != nil {
+			t.Errorf("GetAt(%d): got x = %d, expected = %d, err = %v", i, x, data[i], err)
+		}
+	}
+	for _, i := range []int{-1, 1000, 1023, 1024, 5000} {
+		if _, err := dec.GetAt(i, idx, 64); err == nil {
+			t.Errorf("GetAt(%d) should be out of range", i)
+		}
+	}
+	if _, err := dec.GetAt(0, idx, 6); err == nil {
+		t.Errorf("GetAt with a stride of 6 should fail")
+	}
+}
+
+func TestU32GroupVarintSliceDecoderSkipCorrupt(t *testing.T) {
+	values := make([]uint32, 400)
+	for i := range values {
+		values[i] = uint32(i % 7 * 40)
+	}
+	data := encodeU32GroupVarint(values)
+	offsets := groupVarintOffsets(data)
+	// Make the size byte of group 50 claim four 4 byte values, so it reads zero bytes as leading bytes
+	data[offsets[50]] = 0xff
+	skips := 0
+	skipped := 0
+	dec := NewU32GroupVarintSliceDecoder(data)
+	dec.SkipCorrupt = func(n int) {
+		skips += 1
+		skipped += n
+	}
+	var decoded []uint32
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("GetU32: %s", err)
+		}
+		decoded = append(decoded, x)
+	}
+	if skips == 0 || skipped == 0 {
+		t.Fatalf("Expected a reported skip, got %d skips over %d bytes", skips, skipped)
+	}
+	// Everything before the corrupt group is intact, and decoding realigns with the original groups after it
+	for i := 0; i < 200; i++ {
+		if decoded[i] != values[i] {
+			t.Fatalf("Got x = %d, expected = %d at %d before the corruption", decoded[i], values[i], i)
+		}
+	}
+	tail := 180
+	if len(decoded) < len(values)*9/10 {
+		t.Fatalf("Only %d of %d values were recovered", len(decoded), len(values))
+	}
+	for i := 1; i <= tail; i++ {
+		if decoded[len(decoded)-i] != values[len(values)-i] {
+			t.Fatalf("Got x = %d, expected = %d at %d from the end", decoded[len(decoded)-i], values[len(values)-i], i)
+		}
+	}
+	// Intact data is never skipped
+	dec = NewU32GroupVarintSliceDecoder(encodeU32GroupVarint(randomU32s(401)))
+	dec.SkipCorrupt = func(n int) { t.Errorf("Skipped %d bytes of intact data", n) }
+	for {
+		if _, err := dec.GetU32(); err != nil {
+			break
+		}
+	}
+}
+
+func TestOffsetOfValue(t *testing.T) {
+	values := randomU32s(1002)
+	data := encodeU32GroupVarint(values)
+	// Work out every value's position by hand from the group offsets and control table
+	n := 0
+	for _, off := range groupVarintOffsets(data) {
+		valueOff := 1
+		for _, length := range controlTable[data[off]] {
+			if n == len(values) {
+				break
+			}
+			groupOff, gotValueOff, err := OffsetOfValue(data, n)
+			if err != nil || groupOff != int64(off) || gotValueOff != valueOff {
+				t.Fatalf("OffsetOfValue(%d): got %d, %d, err = %v, expected %d, %d", n, groupOff, gotValueOff, err, off, valueOff)
+			}
+			// The value's bytes really are there
+			x := uint32(0)
+			for _, y := range data[off+valueOff : off+valueOff+int(length)] {
+				x = x<<8 | uint32(y)
+			}
+			if x != values[n] {
+				t.Fatalf("Bytes at value %d decode to %d, expected %d", n, x, values[n])
+			}
+			valueOff += int(length)
+			n += 1
+		}
+	}
+	for _, bad := range []int{-1, len(values), len(values) + 3, len(values) + 100} {
+		if _, _, err := OffsetOfValue(data, bad); err == nil {
+			t.Errorf("OffsetOfValue(%d) should be out of range", bad)
+		}
+	}
+	if _, _, err := OffsetOfValue(data[:len(data)-1], len(values)-1); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated for a cut off final value, got %v", err)
+	}
+}
+
+func TestU32GroupVarintSliceDecoderGetGroupView(t *testing.T) {
+	values := randomU32s(1002)
+	dec := NewU32GroupVarintSliceDecoder(encodeU32GroupVarint(values))
+	// Take one value first, so the first view is the rest of a group
+	if x, err := dec.GetU32(); x != values[0] || err != nil {
+		t.Fatalf("GetU32(): got x = %d, err = %v, expected = %d", x, err, values[0])
+	}
+	decoded := []uint32{values[0]}
+	views := 0
+	for {
+		view, err := dec.GetGroupView()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("GetGroupView: %s", err)
+		}
+		if views == 0 && len(view) != 3 {
+			t.Errorf("First view holds %d values, expected the 3 left in the group", len(view))
+		}
+		// The view is only valid until the next call, so it's copied out here before anything else
+		decoded = append(decoded, view...)
+		views += 1
+	}
+	if views != 251 {
+		t.Errorf("Got %d views, expected 251 for the rest of the first group, 249 full groups and a partial one", views)
+	}
+	if len(decoded) != len(values) {
+		t.Fatalf("%d integers were decoded when %d were encoded", len(decoded), len(values))
+	}
+	for i, expected := range values {
+		if decoded[i] != expected {
+			t.Errorf("Got x = %d, expected = %d at %d", decoded[i], expected, i)
+		}
+	}
+}
+
+func TestU32GroupVarintSliceDecoderSaveState(t *testing.T) {
+	values := randomU32s(11)
+	data := encodeU32GroupVarint(values)
+	dec := NewU32GroupVarintSliceDecoder(data)
+	// Save partway through the first group, so the restore has to bring back the group as well as the offset
+	dec.GetU32()
+	dec.GetU32()
+	state := dec.SaveState()
+	buffered := len(dec.Buffered())
+	for pass := 0; pass < 2; pass++ {
+		for i, expected := range values[2:7] {
+			if x, err := dec.GetU32(); x != expected || err != nil {
+				t.Errorf("Pass %d: got x = %d, err = %v, expected = %d at %d", pass, x, err, expected, i+2)
+			}
+		}
+		dec.Restore(state)
+		if len(dec.Buffered()) != buffered {
+			t.Errorf("Pass %d: Buffered() has %d bytes after Restore, expected %d", pass, len(dec.Buffered()), buffered)
+		}
+	}
+	// Rolling back over the end of the input makes the final partial group readable again
+	for range values[2:] {
+		dec.GetU32()
+	}
+	end := dec.SaveState()
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Fatalf("Expected EOF after every value, got %v", err)
+	}
+	dec.Restore(state)
+	decoded := 0
+	for {
+		if _, err := dec.GetU32(); err != nil {
+			break
+		}
+		decoded += 1
+	}
+	if decoded != len(values)-2 {
+		t.Errorf("Decoded %d values after Restore, expected %d", decoded, len(values)-2)
+	}
+	dec.Restore(end)
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after restoring to the end, got %v", err)
+	}
+}
+
+func TestVerifyU32GroupVarintCount(t *testing.T) {
+	for _, count := range []int{0, 1, 4, 7, 8} {
+		data := encodeU32GroupVarint(randomU32s(count))
+		if err := VerifyU32GroupVarintCount(data, count); err != nil {
+			t.Errorf("Count %d: got err = %v, expected a match", count, err)
+		}
+		for _, wrong := range []int{count - 1, count + 1, count + 4} {
+			if err := VerifyU32GroupVarintCount(data, wrong); !errors.Is(err, ErrCountMismatch) {
+				t.Errorf("Count %d checked against %d: got err = %v, expected ErrCountMismatch", count, wrong, err)
+			}
+		}
+	}
+	// Losing the last whole group still decodes cleanly, and only the count shows what's missing
+	data := encodeU32GroupVarint(randomU32s(8))
+	offsets := groupVarintOffsets(data)
+	if err := VerifyU32GroupVarintCount(data[:offsets[1]], 8); !errors.Is(err, ErrCountMismatch) {
+		t.Errorf("Missing group: got err = %v, expected ErrCountMismatch", err)
+	}
+	// Corrupt data reports its own error
+	if err := VerifyU32GroupVarintCount(data[:len(data)-1], 8); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Cut off value: got err = %v, expected ErrTruncated", err)
+	}
+}
+
+func TestTruncateU32GroupVarint(t *testing.T) {
+	values := randomU32s(10)
+	data := encodeU32GroupVarint(values)
+	for _, n := range []int{0, 3, 4, 6, 7, 8, 10} {
+		truncated, err := TruncateU32GroupVarint(data, n)
+		if err != nil {
+			t.Fatalf("TruncateU32GroupVarint(%d): %s", n, err)
+		}
+		// Truncating is the same as encoding the first n values in the first place
+		if !bytes.Equal(truncated, encodeU32GroupVarint(values[:n])) {
+			t.Errorf("Truncated to %d: got %x, expected %x", n, truncated, encodeU32GroupVarint(values[:n]))
+		}
+		decoded := decodeU32GroupVarint(t, truncated)
+		if len(decoded) != n {
+			t.Fatalf("Truncated to %d: decoded %d values", n, len(decoded))
+		}
+		for i := range decoded {
+			if decoded[i] != values[i] {
+				t.Errorf("Truncated to %d: got x = %d, expected = %d at %d", n, decoded[i], values[i], i)
+			}
+		}
+	}
+	if _, err := TruncateU32GroupVarint(data, 11); err == nil {
+		t.Errorf("Truncating 10 values to 11 should fail")
+	}
+	// The input is left alone
+	if !bytes.Equal(data, encodeU32GroupVarint(values)) {
+		t.Errorf("TruncateU32GroupVarint modified its input")
+	}
+}
+
+func TestU32GroupVarintSliceDecoderSeekToValue(t *testing.T) {
+	values := randomU32s(10)
+	dec := NewU32GroupVarintSliceDecoder(encodeU32GroupVarint(values))
+	expectNext := func(name string, index int) {
+		t.Helper()
+		if x, err := dec.GetU32(); x != values[index] || err != nil {
+			t.Errorf("%s: got x = %d, err = %v, expected = %d at %d", name, x, err, values[index], index)
+		}
+	}
+	if err := dec.SeekToValue(6, io.SeekStart); err != nil {
+		t.Fatalf("SeekStart: %s", err)
+	}
+	expectNext("SeekStart 6", 6)
+	// Now at 7, so back 5 to 2 and forward 3 to 6
+	if err := dec.SeekToValue(-5, io.SeekCurrent); err != nil {
+		t.Fatalf("SeekCurrent: %s", err)
+	}
+	expectNext("SeekCurrent -5", 2)
+	if err := dec.SeekToValue(3, io.SeekCurrent); err != nil {
+		t.Fatalf("SeekCurrent: %s", err)
+	}
+	expectNext("SeekCurrent +3", 6)
+	if err := dec.SeekToValue(-1, io.SeekEnd); err != nil {
+		t.Fatalf("SeekEnd: %s", err)
+	}
+	expectNext("SeekEnd -1", 9)
+	if err := dec.SeekToValue(-10, io.SeekEnd); err != nil {
+		t.Fatalf("SeekEnd: %s", err)
+	}
+	expectNext("SeekEnd -10", 0)
+	if err := dec.SeekToValue(0, io.SeekEnd); err != nil {
+		t.Fatalf("Seeking to the end: %s", err)
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF at the end, got %v", err)
+	}
+	for _, seek := range [][2]int{{11, io.SeekStart}, {-1, io.SeekStart}, {1, io.SeekEnd}, {-11, io.SeekEnd}, {1, 3}} {
+		if err := dec.SeekToValue(seek[0], seek[1]); err == nil {
+			t.Errorf("SeekToValue(%d, %d) should fail", seek[0], seek[1])
+		}
+	}
+	// A failed seek doesn't move the decoder
+	dec.SeekToValue(4, io.SeekStart)
+	dec.SeekToValue(100, io.SeekCurrent)
+	expectNext("After a failed seek", 4)
+}
+
+func TestAnalyzeU32GroupVarint(t *testing.T) {
+	for n := 0; n <= len(testU32); n++ {
+		var buf bytes.Buffer
+		enc := NewU32GroupVarintEncoderWithMetrics(&buf)
+		var expected [4]uint64
+		for _, x := range testU32[:n] {
+			enc.PutU32(x)
+			expected[groupVarintLen(x)-1] += 1
+		}
+		enc.Close()
+		counts, groups, err := AnalyzeU32GroupVarint(buf.Bytes())
+		if err != nil {
+			t.Fatalf("AnalyzeU32GroupVarint of %d values: %s", n, err)
+		}
+		m := enc.Metrics()
+		values, bytes := uint64(0), groups
+		for i, c := range counts {
+			values += c
+			bytes += c * uint64(i+1)
+		}
+		if counts != expected || groups != m.Groups || values != m.Values || bytes != m.Bytes {
+			t.Errorf("AnalyzeU32GroupVarint of %d values: got %v in %d groups, encoder metrics %+v, expected %v", n, counts, groups, m, expected)
+		}
+	}
+	// 300 takes two bytes but only one is left
+	if _, _, err := AnalyzeU32GroupVarint([]byte{0x10, 1, 1}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+}
+
+func TestGetU32WithOffset(t *testing.T) {
+	data := encodeU32GroupVarint(testU32)
+	dec := NewU32GroupVarintSliceDecoder(data)
+	for i, expected := range testU32 {
+		x, off, err := dec.GetU32WithOffset()
+		groupOff, _, offErr := OffsetOfValue(data, i)
+		if x != expected || err != nil || offErr != nil || off != groupOff {
+			t.Errorf("Got x = %d at offset %d, err = %v, expected = %d at offset %d (%v) at %d", x, off, err, expected, groupOff, offErr, i)
+		}
+	}
+	if _, _, err := dec.GetU32WithOffset(); err != io.EOF {
+		t.Errorf("Expected EOF after %d values, got %v", len(testU32), err)
+	}
+}
+
+func TestEqualU32GroupVarint(t *testing.T) {
+	data := encodeU32GroupVarint(testU32)
+	differing := append([]uint32(nil), testU32...)
+	differing[3] += 1
+	// 1 stored in two bytes rather than one
+	padded := []byte{0x40, 0, 1}
+	tests := []struct {
+		a, b     []byte
+		expected bool
+	}{
+		{data, encodeU32GroupVarint(testU32), true},
+		{data, encodeU32GroupVarint(differing), false},
+		{data, encodeU32GroupVarint(testU32[:len(testU32)-1]), false},
+		{encodeU32GroupVarint(testU32[:5]), data, false},
+		{padded, encodeU32GroupVarint([]uint32{1}), true},
+		{nil, nil, true},
+		{nil, padded, false},
+	}
+	for i, test := range tests {
+		if equal, err := EqualU32GroupVarint(test.a, test.b); equal != test.expected || err != nil {
+			t.Errorf("Test %d: got equal = %v, err = %v, expected %v", i, equal, err, test.expected)
+		}
+	}
+	if _, err := EqualU32GroupVarint(data, []byte{0x40, 1}); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+}
+
+func TestHashU32GroupVarint(t *testing.T) {
+	data := encodeU32GroupVarint([]uint32{1, 300, 2})
+	// The same values with 1 and 2 stored in more bytes than they need
+	padded := []byte{0x58, 0, 1, 1, 44, 0, 0, 2}
+	if HashU32GroupVarint(data) != HashU32GroupVarint(padded) {
+		t.Errorf("Two encodings of the same values hash differently")
+	}
+	others := [][]byte{
+		encodeU32GroupVarint([]uint32{1, 300, 3}),
+		encodeU32GroupVarint([]uint32{1, 300}),
+		encodeU32GroupVarint([]uint32{300, 1, 2}),
+		// Cut off partway through 300, after the value 1
+		data[:3],
+	}
+	for i, other := range others {
+		if HashU32GroupVarint(other) == HashU32GroupVarint(data) {
+			t.Errorf("Buffer %d hashes the same as different values", i)
+		}
+	}
+	if HashU32GroupVarint(data[:3]) == HashU32GroupVarint(encodeU32GroupVarint([]uint32{1})) {
+		t.Errorf("A truncated buffer hashes the same as the values before the truncation")
+	}
+}
@@ -0,0 +1,76 @@
+package govarint
+
+import "encoding/binary"
+import "fmt"
+import "io"
+
+// An XOR checksummed stream is group varint followed by the XOR of every value as a little endian uint32.
+// It's much cheaper than a CRC and catches any single changed value, but not values that were
+// reordered, nor changes that cancel each other out.
+
+type U32XorChecksumEncoder struct {
+	w   io.Writer
+	enc *U32GroupVarintEncoder
+	sum uint32
+}
+
+func NewU32XorChecksumEncoder(w io.Writer) *U32XorChecksumEncoder {
+	return &U32XorChecksumEncoder{w: w, enc: NewU32GroupVarintEncoder(w)}
+}
+
+func (b *U32XorChecksumEncoder) PutU32(x uint32) (int, error) {
+	n, err := b.enc.PutU32(x)
+	if err == nil {
+		b.sum ^= x
+	}
+	return n, err
+}
+
+// Close writes any partial group followed by the checksum
+func (b *U32XorChecksumEncoder) Close() error {
+	if b.enc.closed {
+		return nil
+	}
+	if _, err := b.enc.finish(); err != nil {
+		return err
+	}
+	var trailer [4]byte
+	binary.LittleEndian.PutUint32(trailer[:], b.sum)
+	_, err := b.w.Write(trailer[:])
+	return err
+}
+
+///
+
+type U32XorChecksumDecoder struct {
+	dec  *U32GroupVarintSliceDecoder
+	want uint32
+	sum  uint32
+	done bool
+}
+
+// NewU32XorChecksumDecoder reads a whole checksummed stream held in data.
+// The checksum is checked once the values run out, so GetU32 returns ErrChecksumMismatch
+// in place of io.EOF if they don't match it.
+func NewU32XorChecksumDecoder(data []byte) (*U32XorChecksumDecoder, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("govarint: checksummed stream too short for its checksum: %w", ErrTruncated)
+	}
+	want := binary.LittleEndian.Uint32(data[len(data)-4:])
+	return &U32XorChecksumDecoder{dec: NewU32GroupVarintSliceDecoder(data[:len(data)-4]), want: want}, nil
+}
+
+func (b *U32XorChecksumDecoder) GetU32() (uint32, error) {
+	x, err := b.dec.GetU32()
+	if err == io.EOF && !b.done {
+		b.done = true
+		if b.sum != b.want {
+			return 0, fmt.Errorf("govarint: values XOR to %#08x, checksum is %#08x: %w", b.sum, b.want, ErrChecksumMismatch)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	b.sum ^= x
+	return x, nil
+}
@@ -0,0 +1,57 @@
+package govarint
+
+import "bytes"
+import "errors"
+import "io"
+import "testing"
+
+func encodeU32XorChecksum(t *testing.T, xs []uint32) []byte {
+	var buf bytes.Buffer
+	enc := NewU32XorChecksumEncoder(&buf)
+	for _, x := range xs {
+		if _, err := enc.PutU32(x); err != nil {
+			t.Fatalf("PutU32: %s", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	return buf.Bytes()
+}
+
+func TestU32XorChecksumRoundTrip(t *testing.T) {
+	for _, xs := range [][]uint32{nil, fiveU32, testU32} {
+		dec, err := NewU32XorChecksumDecoder(encodeU32XorChecksum(t, xs))
+		if err != nil {
+			t.Fatalf("NewU32XorChecksumDecoder: %s", err)
+		}
+		for i, expected := range xs {
+			if x, err := dec.GetU32(); x != expected || err != nil {
+				t.Errorf("Got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+			}
+		}
+		if _, err := dec.GetU32(); err != io.EOF {
+			t.Errorf("Expected EOF after %d values, got %v", len(xs), err)
+		}
+		if _, err := dec.GetU32(); err != io.EOF {
+			t.Errorf("Expected EOF to repeat, got %v", err)
+		}
+	}
+}
+
+func TestU32XorChecksumDetectsChange(t *testing.T) {
+	data := encodeU32XorChecksum(t, fiveU32)
+	// The first value, 42, is a single byte straight after the size byte
+	data[1] = 43
+	dec, _ := NewU32XorChecksumDecoder(data)
+	var err error
+	for err == nil {
+		_, err = dec.GetU32()
+	}
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Got err = %v, expected ErrChecksumMismatch", err)
+	}
+	if _, err := NewU32XorChecksumDecoder(data[:3]); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Too short for a checksum: got err = %v, expected ErrTruncated", err)
+	}
+}
@@ -0,0 +1,46 @@
+package govarint
+
+import "bufio"
+import "errors"
+import "fmt"
+import "io"
+import "net"
+import "os"
+import "time"
+
+// ConnU32Decoder decodes group varint from a network connection, giving each group a read deadline
+type ConnU32Decoder struct {
+	conn    net.Conn
+	timeout time.Duration
+	dec     *U32GroupVarintDecoder
+	err     error
+}
+
+// NewConnU32Decoder buffers reads from conn and, before decoding each group, sets a read deadline of
+// readTimeout from now, so a stalled peer can hold up a single group for at most that long.
+// A read that times out fails with an error matching ErrReadTimeout. Timing out on the size byte leaves
+// the decoder where it was, so GetU32 can be retried; timing out partway through a group ends the stream,
+// and every later GetU32 returns the same error.
+func NewConnU32Decoder(conn net.Conn, readTimeout time.Duration) *ConnU32Decoder {
+	return &ConnU32Decoder{conn: conn, timeout: readTimeout, dec: NewU32GroupVarintDecoder(bufio.NewReader(conn))}
+}
+
+func (b *ConnU32Decoder) GetU32() (uint32, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.dec.pos == b.dec.capacity && !b.dec.finished {
+		if err := b.conn.SetReadDeadline(time.Now().Add(b.timeout)); err != nil {
+			return 0, err
+		}
+	}
+	x, err := b.dec.GetU32()
+	if err != nil && errors.Is(err, os.ErrDeadlineExceeded) {
+		err = fmt.Errorf("%w: %w", ErrReadTimeout, err)
+	}
+	if err != nil && err != io.EOF && b.dec.finished {
+		// The failure lost part of a group, so the stream can't be resumed
+		b.err = err
+	}
+	return x, err
+}
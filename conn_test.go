@@ -0,0 +1,77 @@
+package govarint
+
+import "errors"
+import "io"
+import "net"
+import "testing"
+import "time"
+
+func TestConnU32Decoder(t *testing.T) {
+	// net.Pipe connections honour read deadlines, so they stand in for a TCP connection
+	client, server := net.Pipe()
+	defer client.Close()
+	data := encodeU32GroupVarint(testU32[:8])
+	go func() {
+		// Send the first group promptly, then stall for longer than the timeout before the second
+		server.Write(data[:groupVarintGroupLen(data[0])])
+		time.Sleep(200 * time.Millisecond)
+		server.Close()
+	}()
+	dec := NewConnU32Decoder(client, 50*time.Millisecond)
+	for i, expected := range testU32[:4] {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Fatalf("Got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+		}
+	}
+	start := time.Now()
+	_, err := dec.GetU32()
+	if !errors.Is(err, ErrReadTimeout) {
+		t.Fatalf("Got err = %v, expected ErrReadTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Timing out took %s with a 50ms timeout", elapsed)
+	}
+}
+
+func TestConnU32DecoderComplete(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	data := encodeU32GroupVarint(testU32)
+	go func() {
+		server.Write(data)
+		server.Close()
+	}()
+	dec := NewConnU32Decoder(client, time.Second)
+	for i, expected := range testU32 {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Fatalf("Got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF once the connection closes, got %v", err)
+	}
+}
+
+func TestConnU32DecoderTimeoutMidGroup(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	go func() {
+		// A size byte and the first value, then a stall past the timeout partway through the group
+		server.Write([]byte{0x00, 0x01})
+		time.Sleep(150 * time.Millisecond)
+		// net.Pipe is unbuffered, so this only completes if the decoder reads on, and fails once client is closed
+		server.Write([]byte{0x02, 0x03, 0x04, 0x00, 0x05, 0x06, 0x07, 0x08})
+		server.Close()
+	}()
+	dec := NewConnU32Decoder(client, 50*time.Millisecond)
+	if _, err := dec.GetU32(); !errors.Is(err, ErrReadTimeout) {
+		t.Fatalf("Got err = %v, expected ErrReadTimeout", err)
+	}
+	// Retrying once the rest is on its way must not pick up partway through the group
+	time.Sleep(150 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if x, err := dec.GetU32(); !errors.Is(err, ErrReadTimeout) {
+			t.Errorf("Retry %d: got x = %d, err = %v, expected ErrReadTimeout", i, x, err)
+		}
+	}
+}
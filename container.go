@@ -0,0 +1,113 @@
+package govarint
+
+import "bytes"
+import "encoding/binary"
+import "errors"
+import "fmt"
+import "io"
+
+// A Container stores many uint32 arrays in one blob, each as its own group varint segment.
+// On Close the segments are followed by an offset table so any array can be decoded on its own:
+//
+//	segment 0 | segment 1 | ... | count, len 0, len 1, ... (base128) | table length (uint32, little endian)
+type Container struct {
+	w       io.Writer
+	buf     bytes.Buffer
+	offsets []int
+	closed  bool
+}
+
+func NewContainer(w io.Writer) *Container {
+	return &Container{w: w, offsets: []int{0}}
+}
+
+// OpenContainer reads a container previously written by Close. The result only supports Get and Len.
+func OpenContainer(data []byte) (*Container, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("govarint: container too short for its table length: %w", ErrTruncated)
+	}
+	tableLen := int(binary.LittleEndian.Uint32(data[len(data)-4:]))
+	if tableLen > len(data)-4 {
+		return nil, fmt.Errorf("govarint: container table length %d exceeds container size: %w", tableLen, ErrTruncated)
+	}
+	segments := data[:len(data)-4-tableLen]
+	table := data[len(segments) : len(data)-4]
+	count, n := binary.Uvarint(table)
+	if n <= 0 {
+		return nil, errors.New("govarint: corrupt container table")
+	}
+	table = table[n:]
+	c := &Container{offsets: []int{0}, closed: true}
+	for i := uint64(0); i < count; i++ {
+		size, n := binary.Uvarint(table)
+		if n <= 0 {
+			return nil, errors.New("govarint: corrupt container table")
+		}
+		table = table[n:]
+		end := c.offsets[len(c.offsets)-1] + int(size)
+		if size > uint64(len(segments)) || end > len(segments) {
+			return nil, fmt.Errorf("govarint: container array %d runs past the end of the data: %w", i, ErrTruncated)
+		}
+		c.offsets = append(c.offsets, end)
+	}
+	c.buf.Write(segments)
+	return c, nil
+}
+
+func (c *Container) AddU32(xs []uint32) error {
+	if c.closed {
+		return ErrClosed
+	}
+	enc := NewU32GroupVarintEncoder(&c.buf)
+	for _, x := range xs {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	c.offsets = append(c.offsets, c.buf.Len())
+	return nil
+}
+
+// Len returns the number of arrays in the container
+func (c *Container) Len() int {
+	return len(c.offsets) - 1
+}
+
+// Get decodes only the i-th array, using the offset table to find its segment
+func (c *Container) Get(i int) ([]uint32, error) {
+	if i < 0 || i >= c.Len() {
+		return nil, fmt.Errorf("govarint: container index %d out of range [0, %d)", i, c.Len())
+	}
+	dec := NewU32GroupVarintSliceDecoder(c.buf.Bytes()[c.offsets[i]:c.offsets[i+1]])
+	var xs []uint32
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			return xs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, x)
+	}
+}
+
+// Close writes the segments followed by the offset table to the underlying writer
+func (c *Container) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	var table []byte
+	tmp := make([]byte, binary.MaxVarintLen64)
+	table = append(table, tmp[:binary.PutUvarint(tmp, uint64(c.Len()))]...)
+	for i := 0; i < c.Len(); i++ {
+		table = append(table, tmp[:binary.PutUvarint(tmp, uint64(c.offsets[i+1]-c.offsets[i]))]...)
+	}
+	binary.LittleEndian.PutUint32(tmp, uint32(len(table)))
+	table = append(table, tmp[:4]...)
+	if _, err := c.w.Write(c.buf.Bytes()); err != nil {
+		return err
+	}
+	_, err := c.w.Write(table)
+	return err
+}
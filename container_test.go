@@ -0,0 +1,48 @@
+package govarint
+
+import "bytes"
+import "testing"
+
+func TestContainerRandomAccess(t *testing.T) {
+	arrays := [][]uint32{
+		fiveU32,
+		{},
+		testU32,
+		fourU32,
+	}
+	var buf bytes.Buffer
+	c := NewContainer(&buf)
+	for _, xs := range arrays {
+		if err := c.AddU32(xs); err != nil {
+			t.Fatalf("AddU32: %s", err)
+		}
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	opened, err := OpenContainer(buf.Bytes())
+	if err != nil {
+		t.Fatalf("OpenContainer: %s", err)
+	}
+	if opened.Len() != len(arrays) {
+		t.Fatalf("Container holds %d arrays when %d were added", opened.Len(), len(arrays))
+	}
+	// Access out of order so each Get has to rely on the offset table
+	for _, i := range []int{2, 0, 3, 1, 2} {
+		xs, err := opened.Get(i)
+		if err != nil {
+			t.Fatalf("Get(%d): %s", i, err)
+		}
+		if len(xs) != len(arrays[i]) {
+			t.Fatalf("Get(%d): %d integers were decoded when %d were added", i, len(xs), len(arrays[i]))
+		}
+		for j, expected := range arrays[i] {
+			if xs[j] != expected {
+				t.Errorf("Get(%d)[%d]: got x = %d, expected = %d", i, j, xs[j], expected)
+			}
+		}
+	}
+	if _, err := opened.Get(len(arrays)); err == nil {
+		t.Errorf("Get(%d) should fail", len(arrays))
+	}
+}
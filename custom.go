@@ -0,0 +1,63 @@
+package govarint
+
+import "encoding/binary"
+import "fmt"
+import "io"
+
+// CustomBase128Decoder reads Base128 varints whose continuation flag is some bit other than the top one.
+// The other seven bits of each byte, in order, carry the value, least significant group first.
+type CustomBase128Decoder struct {
+	r    io.ByteReader
+	cont byte
+}
+
+// NewCustomBase128Decoder decodes varints that continue while continuationBit is set, given as a mask:
+// 0x80, or zero for the default, is ordinary Base128 and 0x01 is the low bit. The mask must be a single bit.
+func NewCustomBase128Decoder(r io.ByteReader, continuationBit uint) (*CustomBase128Decoder, error) {
+	if continuationBit == 0 {
+		continuationBit = 0x80
+	}
+	if continuationBit > 0x80 || continuationBit&(continuationBit-1) != 0 {
+		return nil, fmt.Errorf("govarint: continuation bit %#x is not a single bit of a byte", continuationBit)
+	}
+	return &CustomBase128Decoder{r: r, cont: byte(continuationBit)}, nil
+}
+
+// payload squeezes the continuation bit out of y, leaving the seven value bits
+func (b *CustomBase128Decoder) payload(y byte) uint64 {
+	low := b.cont - 1
+	return uint64(y&low | (y>>1)&^low)
+}
+
+func (b *CustomBase128Decoder) GetU64() (uint64, error) {
+	var x uint64
+	var s uint
+	for i := 0; i < binary.MaxVarintLen64; i++ {
+		y, err := b.r.ReadByte()
+		if err != nil {
+			if i > 0 && err == io.EOF {
+				err = errUnexpectedEOF
+			}
+			return 0, err
+		}
+		p := b.payload(y)
+		if y&b.cont == 0 {
+			// The tenth byte only has room for the top bit of a 64 bit integer
+			if i == binary.MaxVarintLen64-1 && p > 1 {
+				return 0, ErrOverflow
+			}
+			return x | p<<s, nil
+		}
+		x |= p << s
+		s += 7
+	}
+	return 0, ErrOverflow
+}
+
+func (b *CustomBase128Decoder) GetU32() (uint32, error) {
+	v, err := b.GetU64()
+	if err == nil && v > 1<<32-1 {
+		return 0, ErrOverflow
+	}
+	return uint32(v), err
+}
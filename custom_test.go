@@ -0,0 +1,49 @@
+package govarint
+
+import "bytes"
+import "io"
+import "testing"
+
+func TestCustomBase128Decoder(t *testing.T) {
+	var high bytes.Buffer
+	enc := NewU32Base128Encoder(&high)
+	for _, x := range testU32 {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	// The same varints with the continuation flag moved to the low bit and the value bits shifted up
+	low := make([]byte, high.Len())
+	for i, y := range high.Bytes() {
+		low[i] = y<<1 | y>>7
+	}
+	streams := map[uint][]byte{0: high.Bytes(), 0x80: high.Bytes(), 0x01: low}
+	for bit, data := range streams {
+		dec, err := NewCustomBase128Decoder(bytes.NewReader(data), bit)
+		if err != nil {
+			t.Fatalf("NewCustomBase128Decoder(%#x): %s", bit, err)
+		}
+		for i, expected := range testU32 {
+			if x, err := dec.GetU32(); x != expected || err != nil {
+				t.Errorf("Bit %#x: got x = %d, err = %v, expected = %d at %d", bit, x, err, expected, i)
+			}
+		}
+		if _, err := dec.GetU32(); err != io.EOF {
+			t.Errorf("Bit %#x: expected EOF, got %v", bit, err)
+		}
+	}
+	// 300 with the low bit as the flag, worked by hand: 0b0101100 then 0b10, each shifted up a bit
+	dec, _ := NewCustomBase128Decoder(bytes.NewReader([]byte{0x59, 0x04}), 0x01)
+	if x, err := dec.GetU64(); x != 300 || err != nil {
+		t.Errorf("Got x = %d, err = %v, expected 300", x, err)
+	}
+	// A flag in the middle of the byte
+	dec, _ = NewCustomBase128Decoder(bytes.NewReader([]byte{0x10 | 0x0c, 0x02}), 0x10)
+	if x, err := dec.GetU64(); x != 0x0c|0x02<<7 || err != nil {
+		t.Errorf("Got x = %d, err = %v, expected %d", x, err, 0x0c|0x02<<7)
+	}
+	for _, bad := range []uint{0x03, 0x81, 0x100} {
+		if _, err := NewCustomBase128Decoder(bytes.NewReader(nil), bad); err == nil {
+			t.Errorf("Continuation bit %#x should be rejected", bad)
+		}
+	}
+}
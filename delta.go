@@ -0,0 +1,129 @@
+package govarint
+
+import "math"
+
+// Delta encoding stores each value as the difference from the one before it,
+// which keeps sorted sequences such as posting lists small
+
+type U32DeltaEncoder struct {
+	e    U32VarintEncoder
+	prev uint32
+}
+
+// NewU32DeltaEncoder writes the differences between successive values to e.
+// The first value is written as is. Differences wrap around, so unsorted input still round-trips.
+func NewU32DeltaEncoder(e U32VarintEncoder) *U32DeltaEncoder {
+	return &U32DeltaEncoder{e: e}
+}
+
+func (b *U32DeltaEncoder) PutU32(x uint32) (int, error) {
+	delta := x - b.prev
+	b.prev = x
+	return b.e.PutU32(delta)
+}
+
+func (b *U32DeltaEncoder) Close() {
+	b.e.Close()
+}
+
+// finish is Close, returning the error from writing any final partial group if e can report one
+func (b *U32DeltaEncoder) finish() (int, error) {
+	if f, ok := b.e.(finisher); ok {
+		return f.finish()
+	}
+	b.e.Close()
+	return 0, nil
+}
+
+///
+
+type U32DeltaDecoder struct {
+	// StrictMonotonic makes GetU32 fail with ErrNotMonotonic if a value isn't greater than the one before it
+	StrictMonotonic bool
+	// Dedup makes GetU32 skip values equal to the one before it, as from merged posting lists
+	// that both hold the same document. It's applied before the StrictMonotonic check.
+	Dedup bool
+	// CheckOverflow makes GetU32 fail with ErrOverflow if a delta takes the running value past math.MaxUint32.
+	// It's off by default because the encoder writes a smaller value as a delta that wraps around.
+	CheckOverflow bool
+	d             U32VarintDecoder
+	prev          uint32
+	started       bool
+}
+
+func NewU32DeltaDecoder(d U32VarintDecoder) *U32DeltaDecoder {
+	return &U32DeltaDecoder{d: d}
+}
+
+func (b *U32DeltaDecoder) GetU32() (uint32, error) {
+	delta, err := b.d.GetU32()
+	for err == nil && b.Dedup && b.started && delta == 0 {
+		delta, err = b.d.GetU32()
+	}
+	if err != nil {
+		return 0, err
+	}
+	sum := uint64(b.prev) + uint64(delta)
+	if b.CheckOverflow && sum > math.MaxUint32 {
+		return 0, ErrOverflow
+	}
+	x := uint32(sum)
+	if b.StrictMonotonic && b.started && x <= b.prev {
+		return 0, ErrNotMonotonic
+	}
+	b.prev = x
+	b.started = true
+	return x, nil
+}
+
+///
+
+// Frame of reference encoding stores each value as its difference from a fixed base,
+// which suits values clustered in a narrow range far from zero
+
+type U32FOREncoder struct {
+	e    U32VarintEncoder
+	base uint32
+}
+
+// NewU32FOREncoder writes x - base to e for each value. Values below base wrap around,
+// so they still round-trip but take the full four bytes.
+func NewU32FOREncoder(e U32VarintEncoder, base uint32) *U32FOREncoder {
+	return &U32FOREncoder{e: e, base: base}
+}
+
+func (b *U32FOREncoder) PutU32(x uint32) (int, error) {
+	return b.e.PutU32(x - b.base)
+}
+
+func (b *U32FOREncoder) Close() {
+	b.e.Close()
+}
+
+// finish is Close, returning the error from writing any final partial group if e can report one
+func (b *U32FOREncoder) finish() (int, error) {
+	if f, ok := b.e.(finisher); ok {
+		return f.finish()
+	}
+	b.e.Close()
+	return 0, nil
+}
+
+///
+
+type U32FORDecoder struct {
+	d    U32VarintDecoder
+	base uint32
+}
+
+func NewU32FORDecoder(d U32VarintDecoder, base uint32) *U32FORDecoder {
+	return &U32FORDecoder{d: d, base: base}
+}
+
+func (b *U32FORDecoder) GetU32() (uint32, error) {
+	x, err := b.d.GetU32()
+	if err != nil {
+		return 0, err
+	}
+	return x + b.base, nil
+}
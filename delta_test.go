@@ -0,0 +1,120 @@
+package govarint
+
+import "bytes"
+import "io"
+import "testing"
+
+func TestU32DeltaRoundTrip(t *testing.T) {
+	// Out of order values wrap around but still come back out unchanged
+	data := append(append([]uint32{}, testU32...), 5, 1<<32-1, 0)
+	var buf bytes.Buffer
+	enc := NewU32DeltaEncoder(NewU32GroupVarintEncoder(&buf))
+	for _, x := range data {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	dec := NewU32DeltaDecoder(NewU32GroupVarintDecoder(&buf))
+	for i, expected := range data {
+		x, err := dec.GetU32()
+		if x != expected || err != nil {
+			t.Errorf("GetU32() at %d: got x = %d, expected = %d, err = %v", i, x, expected, err)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after %d values, got %v", len(data), err)
+	}
+}
+
+func TestU32DeltaDecoderStrictMonotonic(t *testing.T) {
+	// Raw deltas: 10, +5, +0 (a repeat), then a wrapping delta that goes back to 3
+	deltas := []uint32{10, 5, 0, 1<<32 - 12}
+	data := encodeU32GroupVarint(deltas)
+	dec := NewU32DeltaDecoder(NewU32GroupVarintSliceDecoder(data))
+	dec.StrictMonotonic = true
+	for _, expected := range []uint32{10, 15} {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Errorf("GetU32(): got x = %d, expected = %d, err = %v", x, expected, err)
+		}
+	}
+	if _, err := dec.GetU32(); err != ErrNotMonotonic {
+		t.Errorf("Repeated value: got err = %v, expected = %v", err, ErrNotMonotonic)
+	}
+	// Without the flag the same stream decodes, repeats and wraparound included
+	dec = NewU32DeltaDecoder(NewU32GroupVarintSliceDecoder(data))
+	for _, expected := range []uint32{10, 15, 15, 3} {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Errorf("GetU32(): got x = %d, expected = %d, err = %v", x, expected, err)
+		}
+	}
+	// A decrease is caught as well as a repeat
+	dec = NewU32DeltaDecoder(NewU32GroupVarintSliceDecoder(encodeU32GroupVarint([]uint32{10, 1<<32 - 1})))
+	dec.StrictMonotonic = true
+	dec.GetU32()
+	if _, err := dec.GetU32(); err != ErrNotMonotonic {
+		t.Errorf("Decreasing value: got err = %v, expected = %v", err, ErrNotMonotonic)
+	}
+}
+
+func TestU32DeltaDecoderDedup(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewU32DeltaEncoder(NewU32GroupVarintEncoder(&buf))
+	for _, x := range []uint32{1, 1, 2, 3, 3, 3, 4} {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	dec := NewU32DeltaDecoder(NewU32GroupVarintDecoder(&buf))
+	dec.Dedup = true
+	dec.StrictMonotonic = true
+	for _, expected := range []uint32{1, 2, 3, 4} {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Errorf("GetU32(): got x = %d, expected = %d, err = %v", x, expected, err)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected io.EOF at the end of the stream, got %v", err)
+	}
+}
+
+func TestU32FORRoundTrip(t *testing.T) {
+	// Clustered just above the base, plus one value below it that has to wrap
+	data := []uint32{1000000, 1000007, 1000200, 1000001, 999999, 1000255}
+	var buf bytes.Buffer
+	enc := NewU32FOREncoder(NewU32GroupVarintEncoder(&buf), 1000000)
+	for _, x := range data {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	dec := NewU32FORDecoder(NewU32GroupVarintDecoder(&buf), 1000000)
+	for i, expected := range data {
+		x, err := dec.GetU32()
+		if x != expected || err != nil {
+			t.Errorf("GetU32() at %d: got x = %d, expected = %d, err = %v", i, x, expected, err)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after %d values, got %v", len(data), err)
+	}
+}
+
+func TestU32DeltaDecoderCheckOverflow(t *testing.T) {
+	// The deltas reach math.MaxUint32 exactly, then one more goes past it
+	deltas := []uint32{1<<32 - 10, 9, 0, 1}
+	data := encodeU32GroupVarint(deltas)
+	dec := NewU32DeltaDecoder(NewU32GroupVarintSliceDecoder(data))
+	dec.CheckOverflow = true
+	for _, expected := range []uint32{1<<32 - 10, 1<<32 - 1, 1<<32 - 1} {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Errorf("GetU32(): got x = %d, expected = %d, err = %v", x, expected, err)
+		}
+	}
+	if _, err := dec.GetU32(); err != ErrOverflow {
+		t.Errorf("Delta past the ceiling: got err = %v, expected = %v", err, ErrOverflow)
+	}
+	// Without the flag the last value wraps around to zero
+	dec = NewU32DeltaDecoder(NewU32GroupVarintSliceDecoder(data))
+	for _, expected := range []uint32{1<<32 - 10, 1<<32 - 1, 1<<32 - 1, 0} {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Errorf("GetU32(): got x = %d, expected = %d, err = %v", x, expected, err)
+		}
+	}
+}
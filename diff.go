@@ -0,0 +1,94 @@
+package govarint
+
+import "fmt"
+import "io"
+
+// A diff is a sequence of hunks, each written as Base128 varints:
+// the number of old values to keep unchanged, the number of old values to delete after those,
+// the number of new values to insert in their place, then the inserted values themselves.
+// Whatever follows the last hunk in old is kept as is, so an empty diff means the arrays are equal.
+
+// DiffU32 writes to dst a diff that ApplyU32Diff can use to turn old into new.
+// The diff is a single hunk replacing everything between the common prefix and the common suffix,
+// which is as small as it gets for appends, truncations and edits confined to one region.
+// Scattered edits cost the values in between them.
+func DiffU32(dst io.Writer, old, new []uint32) error {
+	prefix := 0
+	for prefix < len(old) && prefix < len(new) && old[prefix] == new[prefix] {
+		prefix += 1
+	}
+	suffix := 0
+	for suffix < len(old)-prefix && suffix < len(new)-prefix && old[len(old)-1-suffix] == new[len(new)-1-suffix] {
+		suffix += 1
+	}
+	deleted := len(old) - prefix - suffix
+	inserted := new[prefix : len(new)-suffix]
+	if deleted == 0 && len(inserted) == 0 {
+		return nil
+	}
+	enc := NewU64Base128Encoder(dst)
+	for _, x := range []int{prefix, deleted, len(inserted)} {
+		if _, err := enc.PutU64(uint64(x)); err != nil {
+			return err
+		}
+	}
+	for _, x := range inserted {
+		if _, err := enc.PutU32(x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyU32Diff returns a new slice holding old with the diff read from diff applied.
+// old itself isn't modified. An empty old and an empty diff give nil with no error.
+func ApplyU32Diff(old []uint32, diff io.ByteReader) ([]uint32, error) {
+	dec := NewU32Base128Decoder(diff)
+	var out []uint32
+	if len(old) > 0 {
+		out = make([]uint32, 0, len(old))
+	}
+	pos := 0
+	for {
+		keep, err := readUvarint(diff)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		deleted, err := readDiffCount(diff)
+		if err != nil {
+			return nil, err
+		}
+		inserted, err := readDiffCount(diff)
+		if err != nil {
+			return nil, err
+		}
+		if keep > uint64(len(old)-pos) || deleted > uint64(len(old)-pos)-keep {
+			return nil, fmt.Errorf("govarint: diff hunk runs past the end of the %d old values", len(old))
+		}
+		out = append(out, old[pos:pos+int(keep)]...)
+		pos += int(keep) + int(deleted)
+		for i := uint64(0); i < inserted; i++ {
+			x, err := dec.GetU32()
+			if err == io.EOF {
+				err = errUnexpectedEOF
+			}
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, x)
+		}
+	}
+	return append(out, old[pos:]...), nil
+}
+
+// readDiffCount reads a count from the middle of a hunk, where the stream ending is truncation
+func readDiffCount(r io.ByteReader) (uint64, error) {
+	x, err := readUvarint(r)
+	if err == io.EOF {
+		err = errUnexpectedEOF
+	}
+	return x, err
+}
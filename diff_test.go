@@ -0,0 +1,63 @@
+package govarint
+
+import "bytes"
+import "errors"
+import "testing"
+
+func TestDiffU32(t *testing.T) {
+	cases := []struct {
+		name     string
+		old, new []uint32
+	}{
+		{"equal", []uint32{1, 2, 3}, []uint32{1, 2, 3}},
+		{"both empty", nil, nil},
+		{"new empty", []uint32{1, 2, 3}, nil},
+		{"old empty", nil, []uint32{4, 5}},
+		{"append", []uint32{1, 2, 3}, []uint32{1, 2, 3, 4, 5}},
+		{"prepend", []uint32{1, 2, 3}, []uint32{0, 1, 2, 3}},
+		{"superset", []uint32{2, 4, 6}, []uint32{1, 2, 3, 4, 5, 6, 7}},
+		{"replace middle", []uint32{1, 2, 3, 4, 5}, []uint32{1, 9, 9, 9, 5}},
+		{"delete middle", []uint32{1, 2, 3, 4, 5}, []uint32{1, 5}},
+		{"repeated values", []uint32{7, 7, 7}, []uint32{7, 7}},
+		{"random", randomU32s(100), randomU32s(120)[10:]},
+	}
+	for _, c := range cases {
+		var diff bytes.Buffer
+		if err := DiffU32(&diff, c.old, c.new); err != nil {
+			t.Fatalf("%s: DiffU32: %s", c.name, err)
+		}
+		got, err := ApplyU32Diff(c.old, bytes.NewReader(diff.Bytes()))
+		if err != nil {
+			t.Fatalf("%s: ApplyU32Diff: %s", c.name, err)
+		}
+		if len(got) != len(c.new) {
+			t.Fatalf("%s: got %d values, expected %d", c.name, len(got), len(c.new))
+		}
+		for i := range c.new {
+			if got[i] != c.new[i] {
+				t.Errorf("%s: got x = %d, expected = %d at %d", c.name, got[i], c.new[i], i)
+			}
+		}
+	}
+	// Appending costs only the hunk header and the new values
+	var diff bytes.Buffer
+	DiffU32(&diff, []uint32{1, 2, 3}, []uint32{1, 2, 3, 4})
+	if !bytes.Equal(diff.Bytes(), []byte{3, 0, 1, 4}) {
+		t.Errorf("Got diff %x for an append, expected 03000104", diff.Bytes())
+	}
+}
+
+func TestApplyU32DiffCorrupt(t *testing.T) {
+	old := []uint32{1, 2, 3}
+	// Keeping 2 and deleting 2 runs past the end of old
+	if _, err := ApplyU32Diff(old, bytes.NewReader([]byte{2, 2, 0})); err == nil {
+		t.Errorf("Expected an error for a hunk past the end of old")
+	}
+	// Promises two inserted values but only carries one
+	if _, err := ApplyU32Diff(old, bytes.NewReader([]byte{3, 0, 2, 4})); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated for missing inserted values, got %v", err)
+	}
+	if _, err := ApplyU32Diff(old, bytes.NewReader([]byte{3, 0})); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated for a cut off hunk header, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package govarint
+
+import "errors"
+import "fmt"
+import "io"
+
+// Errors returned across the package, for use with errors.Is
+var (
+	// The input ended partway through a value
+	ErrTruncated = errors.New("govarint: truncated input")
+	// A decoded value doesn't fit in the requested integer size
+	ErrOverflow = errors.New("govarint: value overflows integer size")
+	// A value was written to an encoder after Close
+	ErrClosed = errors.New("govarint: encoder is closed")
+	// A value doesn't fit in the remaining space of a fixed buffer
+	ErrShortBuffer = errors.New("govarint: buffer too small")
+	// A varint was encoded with more bytes than necessary
+	ErrNonCanonical = errors.New("govarint: non-canonical varint")
+	// A decoder produced more values than it was allowed to
+	ErrValueLimitExceeded = errors.New("govarint: value limit exceeded")
+	// A decoded value wasn't greater than the one before it
+	ErrNotMonotonic = errors.New("govarint: values are not strictly increasing")
+	// A stream holds a different number of values than it was expected to
+	ErrCountMismatch = errors.New("govarint: value count mismatch")
+	// The values decoded don't match the checksum stored with them
+	ErrChecksumMismatch = errors.New("govarint: checksum mismatch")
+	// A network read didn't complete within its timeout
+	ErrReadTimeout = errors.New("govarint: read timed out")
+	// A decoded dictionary index is past the end of the dictionary
+	ErrIndexOutOfRange = errors.New("govarint: dictionary index out of range")
+)
+
+// errUnexpectedEOF matches both ErrTruncated and io.ErrUnexpectedEOF, so callers checking for either keep working
+var errUnexpectedEOF = fmt.Errorf("%w: %w", ErrTruncated, io.ErrUnexpectedEOF)
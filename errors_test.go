@@ -0,0 +1,193 @@
+package govarint
+
+import "bytes"
+import "errors"
+import "io"
+import "testing"
+
+func TestErrTruncated(t *testing.T) {
+	decoders := map[string]func() error{
+		"Base128": func() error {
+			_, err := NewU64Base128Decoder(bytes.NewReader([]byte{0x80})).GetU64()
+			return err
+		},
+		"GroupVarint size byte only": func() error {
+			_, err := NewU32GroupVarintDecoder(bytes.NewReader([]byte{0x00})).GetU32()
+			return err
+		},
+		"GroupVarint partial value": func() error {
+			// The second value needs two bytes but only one is left
+			_, err := NewU32GroupVarintDecoder(bytes.NewReader([]byte{0x10, 0x01, 0x02})).GetU32()
+			return err
+		},
+		"GroupVarintSlice partial value": func() error {
+			_, err := NewU32GroupVarintSliceDecoder([]byte{0x40, 0x01}).GetU32()
+			return err
+		},
+		"Packed": func() error {
+			_, err := DecodePackedVarints(bytes.NewReader([]byte{0x02, 0x01}))
+			return err
+		},
+		"Nullable": func() error {
+			_, err := NewNullableU32Decoder(bytes.NewReader([]byte{0x09, 0xff}))
+			return err
+		},
+		"Container": func() error {
+			_, err := OpenContainer([]byte{0x01})
+			return err
+		},
+		"Strict": func() error {
+			_, result := DecodeU32AllStrict([]byte{0x80})
+			return result.Err()
+		},
+	}
+	for name, decode := range decoders {
+		err := decode()
+		if !errors.Is(err, ErrTruncated) {
+			t.Errorf("%s: got err = %v, expected ErrTruncated", name, err)
+		}
+	}
+	// Mid-stream truncation should still look like io.ErrUnexpectedEOF to existing callers
+	if err := decoders["Base128"](); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Base128: got err = %v, expected it to match io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestErrOverflow(t *testing.T) {
+	var buf bytes.Buffer
+	NewU64Base128Encoder(&buf).PutU64(1 << 32)
+	if _, err := NewU32Base128Decoder(&buf).GetU32(); !errors.Is(err, ErrOverflow) {
+		t.Errorf("GetU32 of 1 << 32: got err = %v, expected ErrOverflow", err)
+	}
+	// Eleven bytes can't be a 64 bit varint, nor can a tenth byte above one
+	for _, data := range [][]byte{
+		{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01},
+		{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x02},
+	} {
+		if _, err := NewU64Base128Decoder(bytes.NewReader(data)).GetU64(); !errors.Is(err, ErrOverflow) {
+			t.Errorf("GetU64(%v): got err = %v, expected ErrOverflow", data, err)
+		}
+	}
+	max := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}
+	if x, err := NewU64Base128Decoder(bytes.NewReader(max)).GetU64(); x != 1<<64-1 || err != nil {
+		t.Errorf("GetU64 of MaxUint64: got x = %d, err = %v", x, err)
+	}
+	if _, result := DecodeU32AllStrict([]byte{0xff, 0xff, 0xff, 0xff, 0x1f}); !errors.Is(result.Err(), ErrOverflow) {
+		t.Errorf("DecodeU32AllStrict: got err = %v, expected ErrOverflow", result.Err())
+	}
+}
+
+func TestErrClosed(t *testing.T) {
+	var buf bytes.Buffer
+	encoders := map[string]U32VarintEncoder{
+		"Base128":     NewU32Base128Encoder(&buf),
+		"GroupVarint": NewU32GroupVarintEncoder(&buf),
+		"Slice":       NewU32SliceEncoder(make([]byte, 8)),
+	}
+	for name, enc := range encoders {
+		enc.Close()
+		if _, err := enc.PutU32(1); !errors.Is(err, ErrClosed) {
+			t.Errorf("%s: PutU32 after Close got err = %v, expected ErrClosed", name, err)
+		}
+	}
+	block := NewU32BlockEncoder(&buf)
+	block.Close()
+	if _, err := block.PutU32(1); !errors.Is(err, ErrClosed) {
+		t.Errorf("Block: PutU32 after Close got err = %v, expected ErrClosed", err)
+	}
+	c := NewContainer(&buf)
+	c.Close()
+	if err := c.AddU32(fourU32); !errors.Is(err, ErrClosed) {
+		t.Errorf("Container: AddU32 after Close got err = %v, expected ErrClosed", err)
+	}
+}
+
+func TestErrShortBuffer(t *testing.T) {
+	if _, err := NewU32SliceEncoder(make([]byte, 1)).PutU32(1 << 7); !errors.Is(err, ErrShortBuffer) {
+		t.Errorf("PutU32 into a full buffer: got err = %v, expected ErrShortBuffer", err)
+	}
+}
+
+func TestErrNonCanonical(t *testing.T) {
+	if _, result := DecodeU32AllStrict([]byte{0x80, 0x00}); !errors.Is(result.Err(), ErrNonCanonical) {
+		t.Errorf("DecodeU32AllStrict: got err = %v, expected ErrNonCanonical", result.Err())
+	}
+	if _, result := DecodeU32AllStrict([]byte{0x00}); result.Err() != nil {
+		t.Errorf("DecodeU32AllStrict of a single zero: got err = %v", result.Err())
+	}
+}
+
+func TestEmptyInput(t *testing.T) {
+	empty := func() *bytes.Reader { return bytes.NewReader(nil) }
+	helpers := map[string]func() (int, bool, error){
+		"DecodeU32AllStrict": func() (int, bool, error) {
+			xs, result := DecodeU32AllStrict(nil)
+			return len(xs), xs == nil, result.Err()
+		},
+		"DecodeU32AllStrictLimit": func() (int, bool, error) {
+			xs, result := DecodeU32AllStrictLimit([]byte{}, 0)
+			return len(xs), xs == nil, result.Err()
+		},
+		"DecodePackedVarints": func() (int, bool, error) {
+			xs, err := DecodePackedVarints(empty())
+			return len(xs), xs == nil, err
+		},
+		"DecodePackedVarintsLimit": func() (int, bool, error) {
+			xs, err := DecodePackedVarintsLimit(empty(), 0)
+			return len(xs), xs == nil, err
+		},
+		"DecodeRollingU32File": func() (int, bool, error) {
+			xs, err := DecodeRollingU32File([]byte{})
+			return len(xs), xs == nil, err
+		},
+		"ApplyU32Diff": func() (int, bool, error) {
+			xs, err := ApplyU32Diff(nil, empty())
+			return len(xs), xs == nil, err
+		},
+		"DecodeU32Set": func() (int, bool, error) {
+			// The set stays allocated so it can be added to
+			set, err := DecodeU32Set(empty())
+			return len(set), true, err
+		},
+		"DecodeN": func() (int, bool, error) {
+			xs, err := NewU32GroupVarintDecoder(empty()).DecodeN(0)
+			return len(xs), true, err
+		},
+		"MapU32": func() (int, bool, error) {
+			var out bytes.Buffer
+			err := MapU32(&out, empty(), func(x uint32) uint32 { return x })
+			return out.Len(), true, err
+		},
+		"FilterU32": func() (int, bool, error) {
+			var out bytes.Buffer
+			kept, err := FilterU32(&out, empty(), func(uint32) bool { return true })
+			return kept + out.Len(), true, err
+		},
+		"CompactBase128": func() (int, bool, error) {
+			var out bytes.Buffer
+			_, err := CompactBase128(&out, empty())
+			return out.Len(), true, err
+		},
+		"CompactU32GroupVarint": func() (int, bool, error) {
+			var out bytes.Buffer
+			_, err := CompactU32GroupVarint(&out, empty())
+			return out.Len(), true, err
+		},
+		"CopyU32": func() (int, bool, error) {
+			var out bytes.Buffer
+			n, err := CopyU32(NewU32Base128Encoder(&out), NewU32GroupVarintDecoder(empty()))
+			return n + out.Len(), true, err
+		},
+		"DecodeU32ToChan": func() (int, bool, error) {
+			ch := make(chan uint32, 1)
+			err := DecodeU32ToChan(empty(), ch)
+			return len(ch), true, err
+		},
+	}
+	for name, helper := range helpers {
+		n, isNil, err := helper()
+		if n != 0 || !isNil || err != nil {
+			t.Errorf("%s: got %d values (nil = %t), err = %v, expected nil and no error", name, n, isNil, err)
+		}
+	}
+}
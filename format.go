@@ -0,0 +1,28 @@
+package govarint
+
+// FormatKind identifies an encoding in a one byte format header.
+// The values are written to disk, so existing ones must never change.
+type FormatKind uint8
+
+const (
+	FormatBase128 FormatKind = iota + 1
+	FormatGroupVarint
+	// Delta encoding on top of group varint
+	FormatDeltaGroupVarint
+	// Frame of reference on top of group varint, with the base stored in the header
+	FormatFORGroupVarint
+)
+
+func (k FormatKind) String() string {
+	switch k {
+	case FormatBase128:
+		return "Base128"
+	case FormatGroupVarint:
+		return "GroupVarint"
+	case FormatDeltaGroupVarint:
+		return "DeltaGroupVarint"
+	case FormatFORGroupVarint:
+		return "FORGroupVarint"
+	}
+	return "Unknown"
+}
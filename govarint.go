@@ -1,10 +1,13 @@
 package govarint
 
+import "bufio"
+import "bytes"
 import "encoding/binary"
+import "fmt"
 import "io"
 
 type U32VarintEncoder interface {
-	PutU32(x uint32) int
+	PutU32(x uint32) (int, error)
 	Close()
 }
 
@@ -15,7 +18,7 @@ type U32VarintDecoder interface {
 ///
 
 type U64VarintEncoder interface {
-	PutU64(x uint64) int
+	PutU64(x uint64) (int, error)
 	Close()
 }
 
@@ -26,14 +29,89 @@ type U64VarintDecoder interface {
 ///
 
 type U32GroupVarintEncoder struct {
-	w     io.Writer
-	index int
-	store [4]uint32
-	temp  [17]byte
+	w       io.Writer
+	index   int
+	store   [4]uint32
+	temp    [17]byte
+	metrics *EncoderMetrics
+	closed  bool
+	values  uint64
+	// PadOnClose makes Close complete a short final group with PadFill instead of writing a partial group,
+	// so the output is all full groups and fixed-stride. The padding can't be told apart from real values,
+	// so Close also writes the value count in Base128 to PadCountWriter for NewU32PaddedGroupVarintDecoder.
+	PadOnClose     bool
+	PadFill        uint32
+	PadCountWriter io.Writer
+}
+
+// EncoderMetrics counts what an encoder has flushed to its writer.
+// Groups includes a partial final group written by Close.
+type EncoderMetrics struct {
+	Values, Bytes, Groups uint64
 }
 
 func NewU32GroupVarintEncoder(w io.Writer) *U32GroupVarintEncoder { return &U32GroupVarintEncoder{w: w} }
 
+// NewU32GroupVarintEncoderWithMetrics returns an encoder that keeps EncoderMetrics, available from Metrics.
+// Plain encoders skip the bookkeeping.
+func NewU32GroupVarintEncoderWithMetrics(w io.Writer) *U32GroupVarintEncoder {
+	return &U32GroupVarintEncoder{w: w, metrics: &EncoderMetrics{}}
+}
+
+// NewU32GroupVarintEncoderSized is NewU32GroupVarintEncoder for about expectedValues values. If w is a
+// *bytes.Buffer it is grown up front by two bytes a value, a rough average, to save reallocating as it fills.
+// The hint is best-effort: other writers ignore it and a wrong guess only costs an allocation.
+func NewU32GroupVarintEncoderSized(w io.Writer, expectedValues int) *U32GroupVarintEncoder {
+	if buf, ok := w.(*bytes.Buffer); ok && expectedValues > 0 {
+		buf.Grow(expectedValues * 2)
+	}
+	return NewU32GroupVarintEncoder(w)
+}
+
+// Metrics returns the counts so far, which are all zero unless the encoder was created with metrics enabled
+func (b *U32GroupVarintEncoder) Metrics() EncoderMetrics {
+	if b.metrics == nil {
+		return EncoderMetrics{}
+	}
+	return *b.metrics
+}
+
+// groupVarintLen returns the number of bytes group varint uses for x, not counting the size byte
+func groupVarintLen(x uint32) int {
+	switch {
+	case x < 1<<8:
+		return 1
+	case x < 1<<16:
+		return 2
+	case x < 1<<24:
+		return 3
+	}
+	return 4
+}
+
+// controlTable holds the byte length of each of the four values described by a size byte,
+// and controlTableTotal the combined length of all four, not counting the size byte itself
+var controlTable [256][4]uint8
+var controlTableTotal [256]uint8
+
+func init() {
+	for sizeByte := range controlTable {
+		total := uint8(0)
+		for i := range controlTable[sizeByte] {
+			// Two bits per value, first value in the top bits, where 0b00 means one byte
+			length := uint8(sizeByte>>(uint(3-i)*2))&3 + 1
+			controlTable[sizeByte][i] = length
+			total += length
+		}
+		controlTableTotal[sizeByte] = total
+	}
+}
+
+// groupVarintGroupLen returns the number of bytes in a full group with the given size byte, including itself
+func groupVarintGroupLen(sizeByte byte) int {
+	return 1 + int(controlTableTotal[sizeByte])
+}
+
 func (b *U32GroupVarintEncoder) Flush() (int, error) {
 	// TODO: Is it more efficient to have a tailored version that's called only in Close()?
 	// If index is zero, there are no integers to flush
@@ -69,14 +147,23 @@ func (b *U32GroupVarintEncoder) Flush() (int, error) {
 	if b.index != 4 {
 		length -= 4 - b.index
 	}
-	_, err := b.w.Write(b.temp[:length])
+	n, err := b.w.Write(b.temp[:length])
+	if b.metrics != nil {
+		b.metrics.Values += uint64(b.index)
+		b.metrics.Bytes += uint64(n)
+		b.metrics.Groups += 1
+	}
 	return length, err
 }
 
 func (b *U32GroupVarintEncoder) PutU32(x uint32) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
 	bytesWritten := 0
 	b.store[b.index] = x
 	b.index += 1
+	b.values += 1
 	if b.index == 4 {
 		n, err := b.Flush()
 		if err != nil {
@@ -88,9 +175,104 @@ func (b *U32GroupVarintEncoder) PutU32(x uint32) (int, error) {
 	return bytesWritten, nil
 }
 
+// PutU32Func encodes gen(0) through gen(n-1) without collecting the values in a slice first,
+// returning the bytes written by the groups they completed
+func (b *U32GroupVarintEncoder) PutU32Func(n int, gen func(i int) uint32) (int, error) {
+	written := 0
+	for i := 0; i < n; i++ {
+		m, err := b.PutU32(gen(i))
+		written += m
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
 func (b *U32GroupVarintEncoder) Close() {
+	b.finish()
+}
+
+// finish is Close, returning the size and error of writing the final partial group.
+// Wrappers that have to report the error use it in place of Flush and Close, which would write the group twice.
+func (b *U32GroupVarintEncoder) finish() (int, error) {
+	if b.closed {
+		return 0, nil
+	}
+	if b.PadOnClose {
+		return b.finishPadded()
+	}
 	// On Close, we flush any remaining values that might not have been in a full group
-	b.Flush()
+	n, err := b.Flush()
+	b.index = 0
+	b.closed = true
+	return n, err
+}
+
+// finishPadded is finish for PadOnClose, filling out the last group and writing the count alongside
+func (b *U32GroupVarintEncoder) finishPadded() (int, error) {
+	b.closed = true
+	n := 0
+	if b.index > 0 {
+		padding := 4 - b.index
+		for ; b.index < 4; b.index++ {
+			b.store[b.index] = b.PadFill
+		}
+		var err error
+		n, err = b.Flush()
+		b.index = 0
+		if b.metrics != nil {
+			b.metrics.Values -= uint64(padding)
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+	if b.PadCountWriter == nil {
+		return n, nil
+	}
+	var count [binary.MaxVarintLen64]byte
+	_, err := b.PadCountWriter.Write(count[:binary.PutUvarint(count[:], b.values)])
+	return n, err
+}
+
+// Reset makes the encoder write to w as if newly created, so pooled encoders can be reused.
+// Pending values of a partial group are dropped, so Close first to keep them. Metrics, if enabled,
+// start again from zero; the PadOnClose settings are kept.
+func (b *U32GroupVarintEncoder) Reset(w io.Writer) {
+	b.ResetKeepMetrics(w)
+	if b.metrics != nil {
+		*b.metrics = EncoderMetrics{}
+	}
+}
+
+// ResetKeepMetrics is Reset without clearing the metrics, which carry on accumulating across every use
+func (b *U32GroupVarintEncoder) ResetKeepMetrics(w io.Writer) {
+	b.w = w
+	b.index = 0
+	b.values = 0
+	b.closed = false
+}
+
+// EncoderState holds the values an encoder has accepted but not yet written.
+// It has only exported fields so it can be persisted with encoding/gob.
+type EncoderState struct {
+	Pending []uint32
+}
+
+// Snapshot captures the pending, not yet flushed, part of the current group
+func (b *U32GroupVarintEncoder) Snapshot() EncoderState {
+	return EncoderState{Pending: append([]uint32(nil), b.store[:b.index]...)}
+}
+
+// NewU32GroupVarintEncoderFromState returns an encoder that carries on from a Snapshot, writing to w.
+// Whatever was already flushed before the snapshot must already be in the output.
+func NewU32GroupVarintEncoderFromState(w io.Writer, s EncoderState) *U32GroupVarintEncoder {
+	b := NewU32GroupVarintEncoder(w)
+	for _, x := range s.Pending {
+		b.PutU32(x)
+	}
+	return b
 }
 
 ///
@@ -107,58 +289,107 @@ func NewU32GroupVarintDecoder(r io.ByteReader) *U32GroupVarintDecoder {
 	return &U32GroupVarintDecoder{r: r, pos: 4, capacity: 4}
 }
 
+// NewU32GroupVarintDecoderAny decodes from any io.Reader, such as an *os.File, wrapping it in a bufio.Reader
+// if it isn't already an io.ByteReader. The wrapper may read ahead of the values consumed.
+func NewU32GroupVarintDecoderAny(r io.Reader) *U32GroupVarintDecoder {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return NewU32GroupVarintDecoder(br)
+}
+
+// NewU32GroupVarintDecoderAfterCount reads a Base128 value count from r and returns it
+// along with a decoder for the group varint payload that follows
+func NewU32GroupVarintDecoderAfterCount(r io.ByteReader) (*U32GroupVarintDecoder, uint64, error) {
+	count, err := readUvarint(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return NewU32GroupVarintDecoder(r), count, nil
+}
+
+// NewU32GroupVarintDecoderSkip discards a fixed size preamble of headerBytes bytes from r and returns a decoder
+// for the group varint after it. It fails with ErrTruncated if r ends within the header.
+func NewU32GroupVarintDecoderSkip(r io.ByteReader, headerBytes int) (*U32GroupVarintDecoder, error) {
+	if headerBytes < 0 {
+		return nil, fmt.Errorf("govarint: invalid header length %d", headerBytes)
+	}
+	skipped, err := skipBytes(r, headerBytes)
+	if err == io.EOF {
+		err = fmt.Errorf("govarint: input ends after %d bytes of a %d byte header: %w", skipped, headerBytes, ErrTruncated)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewU32GroupVarintDecoder(r), nil
+}
+
 func (b *U32GroupVarintDecoder) getGroup() error {
 	// We should always receive a sizeByte if there are more values to read
 	sizeByte, err := b.r.ReadByte()
 	if err != nil {
 		return err
 	}
-	// Calculate the size of the four incoming 32 bit integers
-	// 0b00 means 1 byte to read, 0b01 = 2, etc
-	b.group[0] = uint32((sizeByte >> 6) & 3)
-	b.group[1] = uint32((sizeByte >> 4) & 3)
-	b.group[2] = uint32((sizeByte >> 2) & 3)
-	b.group[3] = uint32(sizeByte & 3)
-	//
-	for index, size := range b.group {
+	// A size byte of zero, common in runs of small values, is four single byte values that can be read at once
+	if sizeByte == 0 && b.readFourBytes() {
+		b.pos = 0
+		return nil
+	}
+	// Look up the size of the four incoming 32 bit integers
+	for index, size := range &controlTable[sizeByte] {
 		b.group[index] = 0
-		// Any error that occurs in earlier byte reads should be repeated at the end one
+		// The first byte of each value tells us whether the group carries on
+		x, err := b.r.ReadByte()
+		if err == io.EOF {
+			// If we hit EOF here, we have found a partial group
+			// We've return any valid entries we have read and return EOF once we run out
+			b.capacity = index
+			b.finished = true
+			// A size byte with no values after it is never written by the encoder, so the stream was cut short
+			if index == 0 {
+				b.pos = 0
+				return errUnexpectedEOF
+			}
+			break
+		} else if err != nil {
+			// The size byte and any earlier values are gone, so carrying on would misread value bytes as a size byte
+			b.pos = 0
+			b.capacity = 0
+			b.finished = true
+			return err
+		}
+		// Any error that occurs in later byte reads should be repeated at the end one
 		// Hence we only catch and report the final ReadByte's error
-		var err error
 		switch size {
-		case 0:
-			var x byte
-			x, err = b.r.ReadByte()
-			b.group[index] = uint32(x)
 		case 1:
-			var x, y byte
-			x, _ = b.r.ReadByte()
+			b.group[index] = uint32(x)
+		case 2:
+			var y byte
 			y, err = b.r.ReadByte()
 			b.group[index] = uint32(x)<<8 | uint32(y)
-		case 2:
-			var x, y, z byte
-			x, _ = b.r.ReadByte()
+		case 3:
+			var y, z byte
 			y, _ = b.r.ReadByte()
 			z, err = b.r.ReadByte()
 			b.group[index] = uint32(x)<<16 | uint32(y)<<8 | uint32(z)
-		case 3:
-			var x, y, z, zz byte
-			x, _ = b.r.ReadByte()
+		case 4:
+			var y, z, zz byte
 			y, _ = b.r.ReadByte()
 			z, _ = b.r.ReadByte()
 			zz, err = b.r.ReadByte()
 			b.group[index] = uint32(x)<<24 | uint32(y)<<16 | uint32(z)<<8 | uint32(zz)
 		}
 		if err != nil {
+			// Running out partway through a value means the stream was cut short
+			// The values already read from this group are dropped along with it
+			b.pos = 0
+			b.capacity = 0
+			b.finished = true
 			if err == io.EOF {
-				// If we hit EOF here, we have found a partial group
-				// We've return any valid entries we have read and return EOF once we run out
-				b.capacity = index
-				b.finished = true
-				break
-			} else {
-				return err
+				return errUnexpectedEOF
 			}
+			return err
 		}
 	}
 	// Reset the pos pointer to the beginning of the read values
@@ -166,6 +397,32 @@ func (b *U32GroupVarintDecoder) getGroup() error {
 	return nil
 }
 
+// readFourBytes fills the group from the next four bytes in a single read when the reader supports it
+// and has them ready, and otherwise reads nothing and returns false
+func (b *U32GroupVarintDecoder) readFourBytes() bool {
+	var four [4]byte
+	switch r := b.r.(type) {
+	case *bufio.Reader:
+		p, err := r.Peek(4)
+		if err != nil {
+			return false
+		}
+		copy(four[:], p)
+		r.Discard(4)
+	case *bytes.Reader:
+		if r.Len() < 4 {
+			return false
+		}
+		r.Read(four[:])
+	default:
+		return false
+	}
+	for i, y := range four {
+		b.group[i] = uint32(y)
+	}
+	return true
+}
+
 func (b *U32GroupVarintDecoder) GetU32() (uint32, error) {
 	// Check if we have any more values to give out - if not, let's get them
 	if b.pos == b.capacity {
@@ -183,31 +440,629 @@ func (b *U32GroupVarintDecoder) GetU32() (uint32, error) {
 	return b.group[b.pos-1], nil
 }
 
+// GetGroup returns the next group whole, as four values and how many of them are valid, which is only
+// fewer than four for a partial final group, and never zero without an error. Entries past the count are zero.
+// If GetU32 has consumed part of a group, the rest of it comes back first, moved to the front.
+func (b *U32GroupVarintDecoder) GetGroup() ([4]uint32, int, error) {
+	var group [4]uint32
+	if b.pos == b.capacity {
+		if b.finished {
+			return group, 0, io.EOF
+		}
+		if err := b.getGroup(); err != nil {
+			return group, 0, err
+		}
+	}
+	n := copy(group[:], b.group[b.pos:b.capacity])
+	b.pos = b.capacity
+	return group, n, nil
+}
+
+// DebugGroup returns a copy of the decoder's current group and its position in it, for diagnosing
+// partial group and EOF handling. A fresh decoder reports pos == capacity == 4, meaning no group is loaded.
+func (b *U32GroupVarintDecoder) DebugGroup() (group [4]uint32, pos, capacity int, finished bool) {
+	return b.group, b.pos, b.capacity, b.finished
+}
+
+// fill copies as many values as possible into dst a group at a time, returning io.EOF if the stream ends first
+func (b *U32GroupVarintDecoder) fill(dst []uint32) (int, error) {
+	n := 0
+	for n < len(dst) {
+		if b.pos == b.capacity {
+			if b.finished {
+				return n, io.EOF
+			}
+			err := b.getGroup()
+			if err != nil {
+				return n, err
+			}
+			continue
+		}
+		copied := copy(dst[n:], b.group[b.pos:b.capacity])
+		n += copied
+		b.pos += copied
+	}
+	return n, nil
+}
+
+// DecodeStats reads the rest of the stream, returning how many values it held and their min and max.
+// For an empty stream count is zero, min is math.MaxUint32 and max is zero.
+func (b *U32GroupVarintDecoder) DecodeStats() (count uint64, min, max uint32, err error) {
+	min = 1<<32 - 1
+	for {
+		x, err := b.GetU32()
+		if err == io.EOF {
+			return count, min, max, nil
+		}
+		if err != nil {
+			return count, min, max, err
+		}
+		count += 1
+		if x < min {
+			min = x
+		}
+		if x > max {
+			max = x
+		}
+	}
+}
+
+// DecodeN reads exactly n values, for when the count is known from a header or footer.
+// It fails if the stream ends before n values, or if more values follow them.
+func (b *U32GroupVarintDecoder) DecodeN(n int) ([]uint32, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("govarint: negative value count %d", n)
+	}
+	xs := make([]uint32, n)
+	got, err := b.fill(xs)
+	if err == io.EOF {
+		return xs[:got], fmt.Errorf("govarint: stream holds %d values, expected %d: %w", got, n, ErrTruncated)
+	}
+	if err != nil {
+		return xs[:got], err
+	}
+	if _, err := b.GetU32(); err != io.EOF {
+		if err != nil {
+			return xs, err
+		}
+		return xs, fmt.Errorf("govarint: stream holds more than %d values: %w", n, ErrValueLimitExceeded)
+	}
+	return xs, nil
+}
+
+///
+
+type ChunkedU32Decoder struct {
+	dec   *U32GroupVarintDecoder
+	chunk []uint32
+}
+
+// NewChunkedU32Decoder decodes r chunkSize values at a time. A chunkSize below 1 is treated as 1.
+func NewChunkedU32Decoder(r io.ByteReader, chunkSize int) *ChunkedU32Decoder {
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+	return &ChunkedU32Decoder{dec: NewU32GroupVarintDecoder(r), chunk: make([]uint32, chunkSize)}
+}
+
+// NextChunk returns the next chunkSize values, or fewer at the end of the stream, and io.EOF once none are left.
+// The returned slice is reused by the following call, so copy it to keep the values around.
+func (b *ChunkedU32Decoder) NextChunk() ([]uint32, error) {
+	n, err := b.dec.fill(b.chunk)
+	if n > 0 && err == io.EOF {
+		// Hand out the short final chunk now and report EOF on the next call
+		err = nil
+	}
+	if n == 0 && err == nil {
+		err = io.EOF
+	}
+	return b.chunk[:n], err
+}
+
+///
+
+type U32GroupVarintSliceDecoder struct {
+	// SkipCorrupt, if set, turns on best effort recovery. Instead of failing on a group that looks corrupt,
+	// the decoder skips ahead to the next plausible group boundary and calls SkipCorrupt with the number
+	// of bytes passed over. Group varint isn't self-synchronizing, so this is heuristic: a group looks
+	// corrupt if it runs past the end of the data or holds a value the encoder wouldn't have written,
+	// with leading zero bytes, and a boundary is plausible if the next few groups from it don't look corrupt.
+	// Corruption that happens to leave canonical values behind goes unnoticed, and the values decoded
+	// around a skip may be wrong.
+	SkipCorrupt func(skipped int)
+	data        []byte
+	off         int
+	start       int
+	group       [4]uint32
+	ends        [4]int
+	pos         int
+	finished    bool
+	capacity    int
+}
+
+func NewU32GroupVarintSliceDecoder(data []byte) *U32GroupVarintSliceDecoder {
+	return &U32GroupVarintSliceDecoder{data: data, pos: 4, capacity: 4}
+}
+
+// plausibleGroupCheck is how many groups SkipCorrupt requires to look intact before resuming at a boundary
+const plausibleGroupCheck = 4
+
+// plausibleGroup reports whether the group at off looks like one the encoder wrote, and where the next one starts
+func (b *U32GroupVarintSliceDecoder) plausibleGroup(off int) (bool, int) {
+	sizeByte := b.data[off]
+	off += 1
+	for index, length := range &controlTable[sizeByte] {
+		size := int(length)
+		if off == len(b.data) {
+			// A partial group leaves the sizes of its missing values as zero
+			return index > 0 && sizeByte<<(2*index) == 0, off
+		}
+		if off+size > len(b.data) || (size > 1 && b.data[off] == 0) {
+			return false, off
+		}
+		off += size
+	}
+	return true, off
+}
+
+// plausibleBoundary reports whether decoding can resume at off
+func (b *U32GroupVarintSliceDecoder) plausibleBoundary(off int) bool {
+	for i := 0; i < plausibleGroupCheck && off < len(b.data); i++ {
+		ok, next := b.plausibleGroup(off)
+		if !ok {
+			return false
+		}
+		off = next
+	}
+	return true
+}
+
+func (b *U32GroupVarintSliceDecoder) getGroup() error {
+	if b.SkipCorrupt != nil && b.off < len(b.data) {
+		if ok, _ := b.plausibleGroup(b.off); !ok {
+			next := b.off + 1
+			for next < len(b.data) && !b.plausibleBoundary(next) {
+				next += 1
+			}
+			b.SkipCorrupt(next - b.off)
+			b.off = next
+		}
+	}
+	// We should always receive a sizeByte if there are more values to read
+	if b.off >= len(b.data) {
+		return io.EOF
+	}
+	b.start = b.off
+	sizeByte := b.data[b.off]
+	b.off += 1
+	if sizeByte == 0 && b.off+4 <= len(b.data) {
+		for i, y := range b.data[b.off : b.off+4] {
+			b.group[i] = uint32(y)
+			b.ends[i] = b.off + i + 1
+		}
+		b.off += 4
+		b.pos = 0
+		return nil
+	}
+	for index, length := range &controlTable[sizeByte] {
+		size := int(length)
+		if b.off == len(b.data) {
+			// As with the streaming decoder, running out of bytes between values means a partial group
+			b.capacity = index
+			b.finished = true
+			if index == 0 {
+				b.pos = 0
+				return errUnexpectedEOF
+			}
+			break
+		}
+		if b.off+size > len(b.data) {
+			// Running out partway through a value means the input was cut short
+			b.off = len(b.data)
+			b.pos = 0
+			b.capacity = 0
+			b.finished = true
+			return errUnexpectedEOF
+		}
+		x := uint32(0)
+		for _, y := range b.data[b.off : b.off+size] {
+			x = x<<8 | uint32(y)
+		}
+		b.group[index] = x
+		b.off += size
+		b.ends[index] = b.off
+	}
+	// Reset the pos pointer to the beginning of the read values
+	b.pos = 0
+	return nil
+}
+
+func (b *U32GroupVarintSliceDecoder) GetU32() (uint32, error) {
+	// Check if we have any more values to give out - if not, let's get them
+	if b.pos == b.capacity {
+		// If finished is set, there is nothing else to do
+		if b.finished {
+			return 0, io.EOF
+		}
+		err := b.getGroup()
+		if err != nil {
+			return 0, err
+		}
+	}
+	// Increment pointer and return the value stored at that point
+	b.pos += 1
+	return b.group[b.pos-1], nil
+}
+
+// GetU32WithOffset is GetU32, also returning the byte offset of the group the value came from,
+// so a single scan can record where each value lives, as OffsetOfValue would report it
+func (b *U32GroupVarintSliceDecoder) GetU32WithOffset() (value uint32, byteOffset int64, err error) {
+	x, err := b.GetU32()
+	if err != nil {
+		return 0, 0, err
+	}
+	return x, int64(b.start), nil
+}
+
+// GetGroupView returns the values left in the current group, decoding the next group first if none are,
+// and consumes them. It returns io.EOF once the data is exhausted.
+//
+// The returned slice aliases the decoder's internal group buffer rather than being copied out:
+// it's only valid until the next call of any method on the decoder, which may overwrite it.
+// Use the values straight away or copy them; never keep or modify the slice.
+func (b *U32GroupVarintSliceDecoder) GetGroupView() ([]uint32, error) {
+	if b.pos == b.capacity {
+		if b.finished {
+			return nil, io.EOF
+		}
+		if err := b.getGroup(); err != nil {
+			return nil, err
+		}
+	}
+	view := b.group[b.pos:b.capacity]
+	b.pos = b.capacity
+	return view, nil
+}
+
+// DecoderState is a position in a U32GroupVarintSliceDecoder's input, as returned by SaveState
+type DecoderState struct {
+	off      int
+	start    int
+	group    [4]uint32
+	ends     [4]int
+	pos      int
+	finished bool
+	capacity int
+}
+
+// SaveState records the decoder's position, including any partly consumed group, so it can be rolled back
+// with Restore. It copies a few words and doesn't allocate.
+func (b *U32GroupVarintSliceDecoder) SaveState() DecoderState {
+	return DecoderState{off: b.off, start: b.start, group: b.group, ends: b.ends, pos: b.pos, finished: b.finished, capacity: b.capacity}
+}
+
+// Restore moves the decoder back to a state saved from it by SaveState
+func (b *U32GroupVarintSliceDecoder) Restore(s DecoderState) {
+	b.off = s.off
+	b.start = s.start
+	b.group = s.group
+	b.ends = s.ends
+	b.pos = s.pos
+	b.finished = s.finished
+	b.capacity = s.capacity
+}
+
+// SeekToByte moves the decoder to off, which must be the start of a group.
+// Any partially consumed group is discarded.
+func (b *U32GroupVarintSliceDecoder) SeekToByte(off int) error {
+	if off < 0 || off > len(b.data) {
+		return fmt.Errorf("govarint: seek offset %d out of range [0, %d]", off, len(b.data))
+	}
+	b.off = off
+	b.pos = 4
+	b.capacity = 4
+	b.finished = false
+	return nil
+}
+
+// valuesBefore counts the values in the groups that start before off, reading only size bytes
+func (b *U32GroupVarintSliceDecoder) valuesBefore(off int) int {
+	count := 0
+	for g := 0; g < off && g < len(b.data); {
+		end := g + groupVarintGroupLen(b.data[g])
+		if end <= len(b.data) {
+			count += 4
+			g = end
+			continue
+		}
+		// A partial final group holds however many whole values fit
+		g += 1
+		for _, length := range &controlTable[b.data[g-1]] {
+			if g+int(length) > len(b.data) {
+				break
+			}
+			g += int(length)
+			count += 1
+		}
+		break
+	}
+	return count
+}
+
+// SeekToValue moves the decoder so the next GetU32 returns value n counted from whence, which is
+// io.SeekStart, io.SeekCurrent or io.SeekEnd as for io.Seeker, but in values rather than bytes.
+// Seeking to the end is allowed and leaves nothing to read; seeking outside the values is an error.
+// Positions are found by scanning size bytes from the start of the data, so each seek costs a pass over
+// the groups before it, and SeekEnd a pass over all of them; no values are decoded but those before the
+// target in its own group.
+func (b *U32GroupVarintSliceDecoder) SeekToValue(n int, whence int) error {
+	target := n
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		target += b.valuesBefore(b.off) - (b.capacity - b.pos)
+	case io.SeekEnd:
+		target += b.valuesBefore(len(b.data))
+	default:
+		return fmt.Errorf("govarint: invalid whence %d", whence)
+	}
+	if total := b.valuesBefore(len(b.data)); target < 0 || target > total {
+		return fmt.Errorf("govarint: value position %d out of range [0, %d]", target, total)
+	}
+	groupOff, _, err := OffsetOfValue(b.data, target)
+	if err != nil {
+		// The end, where there's no value to find
+		return b.SeekToByte(len(b.data))
+	}
+	if err := b.SeekToByte(int(groupOff)); err != nil {
+		return err
+	}
+	for i := 0; i < target%4; i++ {
+		if _, err := b.GetU32(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAt returns the value at position index using a group offset index, where idx[k] is the byte offset
+// of the group that starts with value k*stride and stride is a multiple of four. It seeks to the nearest
+// indexed group at or before index and decodes forward from there, so at most stride values are decoded.
+// The decoder is left positioned just after the returned value.
+func (b *U32GroupVarintSliceDecoder) GetAt(index int, idx []int64, stride int) (uint32, error) {
+	if stride <= 0 || stride%4 != 0 {
+		return 0, fmt.Errorf("govarint: index stride %d is not a positive multiple of 4", stride)
+	}
+	if index < 0 || index/stride >= len(idx) {
+		return 0, fmt.Errorf("govarint: value index %d out of range", index)
+	}
+	if err := b.SeekToByte(int(idx[index/stride])); err != nil {
+		return 0, err
+	}
+	var x uint32
+	for i := 0; i <= index%stride; i++ {
+		var err error
+		x, err = b.GetU32()
+		if err == io.EOF {
+			return 0, fmt.Errorf("govarint: value index %d out of range", index)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return x, nil
+}
+
+// Buffered returns the part of the input that hasn't been consumed by GetU32.
+// Values left over in the current group are counted as unconsumed, so after reading
+// exactly as many values as were encoded, Buffered begins right after the integer stream.
+func (b *U32GroupVarintSliceDecoder) Buffered() []byte {
+	if b.pos < b.capacity {
+		return b.data[b.ends[b.pos-1]:]
+	}
+	return b.data[b.off:]
+}
+
 ///
 
 type Base128Encoder struct {
 	w        io.Writer
+	bw       io.ByteWriter
 	tmpBytes []byte
+	batch    []byte
+	minBytes int
+	closed   bool
+}
+
+// EncodedLenU32 returns the number of bytes Base128 uses to encode x
+func EncodedLenU32(x uint32) int {
+	n := 1
+	for x >= 0x80 {
+		x >>= 7
+		n += 1
+	}
+	return n
 }
 
+// If w is also an io.ByteWriter (such as a *bufio.Writer), bytes are written one at a time
+// straight into it and no scratch slice is needed
 func NewU32Base128Encoder(w io.Writer) *Base128Encoder {
+	if bw, ok := w.(io.ByteWriter); ok {
+		return &Base128Encoder{w: w, bw: bw}
+	}
 	return &Base128Encoder{w: w, tmpBytes: make([]byte, binary.MaxVarintLen32)}
 }
 func NewU64Base128Encoder(w io.Writer) *Base128Encoder {
+	if bw, ok := w.(io.ByteWriter); ok {
+		return &Base128Encoder{w: w, bw: bw}
+	}
 	return &Base128Encoder{w: w, tmpBytes: make([]byte, binary.MaxVarintLen64)}
 }
 
+// MinBytes makes the encoder write every value in at least k bytes, up to binary.MaxVarintLen64,
+// for fixed layouts that need the alignment, and returns b. Short values are padded with continuation
+// bytes (1 in three bytes is 0x81 0x80 0x00), which is non-canonical but valid Base128: the decoders here
+// read it, while DecodeU32AllStrict reports it as DecodeNonCanonical.
+func (b *Base128Encoder) MinBytes(k int) *Base128Encoder {
+	if k > binary.MaxVarintLen64 {
+		k = binary.MaxVarintLen64
+	}
+	b.minBytes = k
+	if len(b.tmpBytes) < binary.MaxVarintLen64 {
+		b.tmpBytes = make([]byte, binary.MaxVarintLen64)
+	}
+	return b
+}
+
+// appendPaddedUvarint appends x to buf in Base128, padded to at least minBytes bytes
+func appendPaddedUvarint(buf []byte, x uint64, minBytes int) []byte {
+	start := len(buf)
+	buf = binary.AppendUvarint(buf, x)
+	for len(buf)-start < minBytes {
+		buf[len(buf)-1] |= 0x80
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+// writePadded writes x padded to minBytes in a single Write
+func (b *Base128Encoder) writePadded(x uint64) (int, error) {
+	return b.w.Write(appendPaddedUvarint(b.tmpBytes[:0], x, b.minBytes))
+}
+
+func (b *Base128Encoder) writeUvarint(x uint64) (int, error) {
+	n := 0
+	for x >= 0x80 {
+		if err := b.bw.WriteByte(byte(x) | 0x80); err != nil {
+			return n, err
+		}
+		x >>= 7
+		n += 1
+	}
+	if err := b.bw.WriteByte(byte(x)); err != nil {
+		return n, err
+	}
+	return n + 1, nil
+}
+
 func (b *Base128Encoder) PutU32(x uint32) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	if b.minBytes > 1 {
+		return b.writePadded(uint64(x))
+	}
+	if b.bw != nil {
+		return b.writeUvarint(uint64(x))
+	}
 	writtenBytes := binary.PutUvarint(b.tmpBytes, uint64(x))
 	return b.w.Write(b.tmpBytes[:writtenBytes])
 }
 
+// PutU32s encodes all of xs with a single Write to the underlying writer.
+// On a short write, the returned count is the number of bytes the writer accepted.
+func (b *Base128Encoder) PutU32s(xs []uint32) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	size := 0
+	for _, x := range xs {
+		if n := EncodedLenU32(x); n > b.minBytes {
+			size += n
+		} else {
+			size += b.minBytes
+		}
+	}
+	// The scratch buffer is kept between calls so repeated batches don't allocate
+	if cap(b.batch) < size {
+		b.batch = make([]byte, size)
+	}
+	buf := b.batch[:0]
+	for _, x := range xs {
+		buf = appendPaddedUvarint(buf, uint64(x), b.minBytes)
+	}
+	return b.w.Write(buf)
+}
+
+// generatedBatchSize is how many bytes PutU32Func encodes before handing them to the writer
+const generatedBatchSize = 64 << 10
+
+// PutU32Func encodes gen(0) through gen(n-1) without collecting the values in a slice first.
+// Like PutU32s it goes through the batch buffer, writing once per 64KB of output rather than per value,
+// and returns the number of bytes the writer accepted.
+func (b *Base128Encoder) PutU32Func(n int, gen func(i int) uint32) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	written := 0
+	buf := b.batch[:0]
+	for i := 0; i < n; i++ {
+		buf = appendPaddedUvarint(buf, uint64(gen(i)), b.minBytes)
+		if len(buf) >= generatedBatchSize || i == n-1 {
+			m, err := b.w.Write(buf)
+			written += m
+			if err != nil {
+				return written, err
+			}
+			buf = buf[:0]
+		}
+	}
+	b.batch = buf
+	return written, nil
+}
+
 func (b *Base128Encoder) PutU64(x uint64) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	if b.minBytes > 1 {
+		return b.writePadded(x)
+	}
+	if b.bw != nil {
+		return b.writeUvarint(x)
+	}
 	writtenBytes := binary.PutUvarint(b.tmpBytes, x)
 	return b.w.Write(b.tmpBytes[:writtenBytes])
 }
 
 func (b *Base128Encoder) Close() {
+	b.closed = true
+}
+
+///
+
+// U32SliceEncoder writes Base128 values into a fixed, caller supplied buffer
+type U32SliceEncoder struct {
+	buf    []byte
+	off    int
+	closed bool
+}
+
+func NewU32SliceEncoder(buf []byte) *U32SliceEncoder {
+	return &U32SliceEncoder{buf: buf}
+}
+
+// PutU32 either writes all of x or nothing at all. If x doesn't fit, the buffer and cursor
+// are left untouched and ErrShortBuffer is returned, so the caller can retry with more room.
+func (b *U32SliceEncoder) PutU32(x uint32) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	if len(b.buf)-b.off < EncodedLenU32(x) {
+		return 0, ErrShortBuffer
+	}
+	n := binary.PutUvarint(b.buf[b.off:], uint64(x))
+	b.off += n
+	return n, nil
+}
+
+// Bytes returns the encoded values written so far
+func (b *U32SliceEncoder) Bytes() []byte {
+	return b.buf[:b.off]
+}
+
+func (b *U32SliceEncoder) Close() {
+	b.closed = true
 }
 
 ///
@@ -219,11 +1074,53 @@ type Base128Decoder struct {
 func NewU32Base128Decoder(r io.ByteReader) *Base128Decoder { return &Base128Decoder{r: r} }
 func NewU64Base128Decoder(r io.ByteReader) *Base128Decoder { return &Base128Decoder{r: r} }
 
+// readUvarint is binary.ReadUvarint, but reporting problems with the package's errors
+func readUvarint(r io.ByteReader) (uint64, error) {
+	var x uint64
+	var s uint
+	for i := 0; i < binary.MaxVarintLen64; i++ {
+		y, err := r.ReadByte()
+		if err != nil {
+			if i > 0 && err == io.EOF {
+				err = errUnexpectedEOF
+			}
+			return 0, err
+		}
+		if y < 0x80 {
+			// The tenth byte only has room for the top bit of a 64 bit integer
+			if i == binary.MaxVarintLen64-1 && y > 1 {
+				return 0, ErrOverflow
+			}
+			return x | uint64(y)<<s, nil
+		}
+		x |= uint64(y&0x7f) << s
+		s += 7
+	}
+	return 0, ErrOverflow
+}
+
 func (b *Base128Decoder) GetU32() (uint32, error) {
-	v, err := binary.ReadUvarint(b.r)
+	v, err := readUvarint(b.r)
+	if err == nil && v > 1<<32-1 {
+		return 0, ErrOverflow
+	}
 	return uint32(v), err
 }
 
 func (b *Base128Decoder) GetU64() (uint64, error) {
-	return binary.ReadUvarint(b.r)
+	return readUvarint(b.r)
+}
+
+// GetU64s fills dst with the next len(dst) values and returns how many were read.
+// If the stream ends first, the short count is returned along with io.EOF.
+// A corrupt value stops the batch, returning the values before it and the error.
+func (b *Base128Decoder) GetU64s(dst []uint64) (int, error) {
+	for i := range dst {
+		x, err := readUvarint(b.r)
+		if err != nil {
+			return i, err
+		}
+		dst[i] = x
+	}
+	return len(dst), nil
 }
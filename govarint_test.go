@@ -1,6 +1,10 @@
 package govarint
 
+import "bufio"
 import "bytes"
+import "encoding/binary"
+import "encoding/gob"
+import "errors"
 import "io"
 import "math/rand"
 import "testing"
@@ -257,3 +261,747 @@ func BenchmarkGroupVarint(b *testing.B) {
 		speedTest(b, dec, readBuf, expectedTotal)
 	}
 }
+
+func TestU32GroupVarintSliceDecoderSeekToByte(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewU32GroupVarintEncoder(&buf)
+	for _, x := range testU32 {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	dec := NewU32GroupVarintSliceDecoder(buf.Bytes())
+	for i, expected := range testU32 {
+		x, err := dec.GetU32()
+		if x != expected || err != nil {
+			t.Errorf("GetU32() at %d: got x = %d, expected = %d, err = %s", i, x, expected, err)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after %d values, got %v", len(testU32), err)
+	}
+	// The first group holds 0, 1, 2, 10, each a single byte, so the second group starts at byte 5
+	if err := dec.SeekToByte(5); err != nil {
+		t.Fatalf("SeekToByte(5): %s", err)
+	}
+	for _, expected := range testU32[4:8] {
+		x, err := dec.GetU32()
+		if x != expected || err != nil {
+			t.Errorf("GetU32() after seek: got x = %d, expected = %d, err = %s", x, expected, err)
+		}
+	}
+	if err := dec.SeekToByte(-1); err == nil {
+		t.Errorf("SeekToByte(-1) should fail")
+	}
+	if err := dec.SeekToByte(buf.Len() + 1); err == nil {
+		t.Errorf("SeekToByte(%d) should fail", buf.Len()+1)
+	}
+}
+
+type shortWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) <= w.limit {
+		return w.buf.Write(p)
+	}
+	n, _ := w.buf.Write(p[:w.limit-w.buf.Len()])
+	return n, io.ErrShortWrite
+}
+
+func TestBase128PutU32s(t *testing.T) {
+	expectedLen := 0
+	for _, x := range fiveU32 {
+		expectedLen += EncodedLenU32(x)
+	}
+	var buf bytes.Buffer
+	enc := NewU32Base128Encoder(&buf)
+	n, err := enc.PutU32s(fiveU32)
+	if n != expectedLen || err != nil {
+		t.Errorf("PutU32s: got n = %d, err = %v, expected n = %d", n, err, expectedLen)
+	}
+	enc.Close()
+	dec := NewU32Base128Decoder(&buf)
+	for i, expected := range fiveU32 {
+		x, err := dec.GetU32()
+		if x != expected || err != nil {
+			t.Errorf("GetU32() at %d: got x = %d, expected = %d, err = %s", i, x, expected, err)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after %d values, got %v", len(fiveU32), err)
+	}
+	// Only part of the batch fits, and the count should say exactly how much
+	w := &shortWriter{limit: expectedLen - 3}
+	enc = NewU32Base128Encoder(w)
+	n, err = enc.PutU32s(fiveU32)
+	if n != expectedLen-3 || err != io.ErrShortWrite {
+		t.Errorf("PutU32s on a short writer: got n = %d, err = %v, expected n = %d, err = %v", n, err, expectedLen-3, io.ErrShortWrite)
+	}
+}
+
+func TestU32GroupVarintSliceDecoderBuffered(t *testing.T) {
+	trailer := []byte("trailer")
+	for n := 0; n <= len(fiveU32); n++ {
+		var buf bytes.Buffer
+		enc := NewU32GroupVarintEncoder(&buf)
+		for _, x := range fiveU32[:n] {
+			enc.PutU32(x)
+		}
+		enc.Close()
+		buf.Write(trailer)
+		dec := NewU32GroupVarintSliceDecoder(buf.Bytes())
+		for i, expected := range fiveU32[:n] {
+			x, err := dec.GetU32()
+			if x != expected || err != nil {
+				t.Errorf("GetU32() at %d: got x = %d, expected = %d, err = %s", i, x, expected, err)
+			}
+		}
+		if rest := dec.Buffered(); !bytes.Equal(rest, trailer) {
+			t.Errorf("Buffered() after %d values: got %v, expected %v", n, rest, trailer)
+		}
+	}
+}
+
+var _ U32VarintEncoder = (*U32GroupVarintEncoder)(nil)
+var _ U32VarintEncoder = (*Base128Encoder)(nil)
+var _ U32VarintEncoder = (*U32SliceEncoder)(nil)
+var _ U64VarintEncoder = (*Base128Encoder)(nil)
+var _ U32VarintDecoder = (*U32GroupVarintDecoder)(nil)
+var _ U32VarintDecoder = (*U32GroupVarintSliceDecoder)(nil)
+var _ U32VarintDecoder = (*Base128Decoder)(nil)
+var _ U64VarintDecoder = (*Base128Decoder)(nil)
+
+func TestU32GroupVarintSizeByteOnly(t *testing.T) {
+	// A lone size byte promises at least one value, so this is a truncated stream rather than an empty one
+	data := []byte{0x00}
+	decoders := map[string]U32VarintDecoder{
+		"stream": NewU32GroupVarintDecoder(bytes.NewReader(data)),
+		"slice":  NewU32GroupVarintSliceDecoder(data),
+	}
+	for name, dec := range decoders {
+		if _, err := dec.GetU32(); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("%s: got err = %v, expected = %v", name, err, io.ErrUnexpectedEOF)
+		}
+		if _, err := dec.GetU32(); err != io.EOF {
+			t.Errorf("%s: second GetU32() got err = %v, expected = EOF", name, err)
+		}
+	}
+}
+
+// writerOnly hides any io.ByteWriter implementation so encoders take the slice path
+type writerOnly struct {
+	w io.Writer
+}
+
+func (w writerOnly) Write(p []byte) (int, error) {
+	return w.w.Write(p)
+}
+
+func TestBase128ByteWriterAndSlicePaths(t *testing.T) {
+	var direct, sliced bytes.Buffer
+	paths := map[string]*Base128Encoder{
+		"ByteWriter": NewU64Base128Encoder(&direct),
+		"slice":      NewU64Base128Encoder(writerOnly{&sliced}),
+	}
+	if paths["ByteWriter"].bw == nil || paths["slice"].bw != nil {
+		t.Fatalf("Encoders didn't pick the expected write path")
+	}
+	for name, enc := range paths {
+		for _, x := range testU64 {
+			n, err := enc.PutU64(x)
+			if err != nil || n == 0 {
+				t.Errorf("%s: PutU64(%d) got n = %d, err = %v", name, x, n, err)
+			}
+		}
+		enc.Close()
+	}
+	if !bytes.Equal(direct.Bytes(), sliced.Bytes()) {
+		t.Fatalf("ByteWriter path wrote %v, slice path wrote %v", direct.Bytes(), sliced.Bytes())
+	}
+	dec := NewU64Base128Decoder(&direct)
+	for i, expected := range testU64 {
+		x, err := dec.GetU64()
+		if x != expected || err != nil {
+			t.Errorf("GetU64() at %d: got x = %d, expected = %d, err = %s", i, x, expected, err)
+		}
+	}
+}
+
+func BenchmarkBase128BufioWriter(b *testing.B) {
+	_, data := generateRandomU14()
+	w := bufio.NewWriter(io.Discard)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc := NewU32Base128Encoder(w)
+		for _, x := range data {
+			enc.PutU32(x)
+		}
+		enc.Close()
+		w.Flush()
+	}
+}
+
+func BenchmarkBase128SlicePath(b *testing.B) {
+	_, data := generateRandomU14()
+	w := writerOnly{bufio.NewWriter(io.Discard)}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc := NewU32Base128Encoder(w)
+		for _, x := range data {
+			enc.PutU32(x)
+		}
+		enc.Close()
+	}
+}
+
+// The sized encoder should show fewer allocations per op, as the buffer is mostly grown up front
+func benchmarkGroupVarintEncodeBuffer(b *testing.B, sized bool) {
+	_, data := generateRandomU14()
+	data = data[:100000]
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var buf bytes.Buffer
+		enc := NewU32GroupVarintEncoder(&buf)
+		if sized {
+			enc = NewU32GroupVarintEncoderSized(&buf, len(data))
+		}
+		for _, x := range data {
+			enc.PutU32(x)
+		}
+		enc.Close()
+	}
+}
+
+func BenchmarkGroupVarintEncodeBuffer(b *testing.B)      { benchmarkGroupVarintEncodeBuffer(b, false) }
+func BenchmarkGroupVarintEncodeBufferSized(b *testing.B) { benchmarkGroupVarintEncodeBuffer(b, true) }
+
+// readerOnly hides everything but Read, as a file or network connection would
+type readerOnly struct {
+	r io.Reader
+}
+
+func (r readerOnly) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+func TestU32GroupVarintDecoderAny(t *testing.T) {
+	data := encodeU32GroupVarint(testU32)
+	for _, r := range []io.Reader{readerOnly{bytes.NewReader(data)}, bytes.NewReader(data)} {
+		dec := NewU32GroupVarintDecoderAny(r)
+		for i, expected := range testU32 {
+			if x, err := dec.GetU32(); x != expected || err != nil {
+				t.Errorf("Got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+			}
+		}
+		if _, err := dec.GetU32(); err != io.EOF {
+			t.Errorf("Expected EOF after %d values, got %v", len(testU32), err)
+		}
+	}
+	if _, ok := NewU32GroupVarintDecoderAny(bytes.NewReader(data)).r.(*bytes.Reader); !ok {
+		t.Errorf("A reader that is already an io.ByteReader shouldn't be wrapped")
+	}
+}
+
+func TestU32GroupVarintEncoderMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewU32GroupVarintEncoderWithMetrics(&buf)
+	for _, x := range testU32[:10] {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	// Two full groups and one partial group of two
+	expected := EncoderMetrics{Values: 10, Bytes: uint64(buf.Len()), Groups: 3}
+	if m := enc.Metrics(); m != expected {
+		t.Errorf("Metrics(): got %+v, expected %+v", m, expected)
+	}
+	plain := NewU32GroupVarintEncoder(&buf)
+	plain.PutU32(1)
+	plain.Close()
+	if m := plain.Metrics(); m != (EncoderMetrics{}) {
+		t.Errorf("Metrics() without metrics enabled: got %+v, expected zero", m)
+	}
+}
+
+func TestU32GroupVarintEncoderReset(t *testing.T) {
+	var first, second bytes.Buffer
+	enc := NewU32GroupVarintEncoderWithMetrics(&first)
+	for _, x := range testU32[:10] {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	once := enc.Metrics()
+	enc.ResetKeepMetrics(&second)
+	for _, x := range testU32[:10] {
+		if _, err := enc.PutU32(x); err != nil {
+			t.Fatalf("PutU32 after ResetKeepMetrics: %s", err)
+		}
+	}
+	enc.Close()
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Errorf("Encoding after ResetKeepMetrics gave %x, expected %x", second.Bytes(), first.Bytes())
+	}
+	expected := EncoderMetrics{Values: 2 * once.Values, Bytes: 2 * once.Bytes, Groups: 2 * once.Groups}
+	if m := enc.Metrics(); m != expected {
+		t.Errorf("Metrics() after ResetKeepMetrics: got %+v, expected %+v", m, expected)
+	}
+	// A pending value is dropped along with the metrics
+	enc.PutU32(1)
+	enc.Reset(&second)
+	if m := enc.Metrics(); m != (EncoderMetrics{}) {
+		t.Errorf("Metrics() after Reset: got %+v, expected zero", m)
+	}
+	second.Reset()
+	enc.PutU32(7)
+	enc.Close()
+	if !bytes.Equal(second.Bytes(), []byte{0, 7}) {
+		t.Errorf("Encoding after Reset gave %x, expected 0007", second.Bytes())
+	}
+	if m := enc.Metrics(); m != (EncoderMetrics{Values: 1, Bytes: 2, Groups: 1}) {
+		t.Errorf("Metrics() after Reset and one value: got %+v", m)
+	}
+}
+
+func TestU32GroupVarintDecoderGetGroup(t *testing.T) {
+	data := encodeU32GroupVarint(testU32[:10])
+	dec := NewU32GroupVarintDecoder(bytes.NewReader(data))
+	for i, expected := range []int{4, 4, 2} {
+		group, n, err := dec.GetGroup()
+		if n != expected || err != nil {
+			t.Fatalf("GetGroup() %d: got n = %d, err = %v, expected n = %d", i, n, err, expected)
+		}
+		var want [4]uint32
+		copy(want[:], testU32[4*i:4*i+n])
+		if group != want {
+			t.Errorf("GetGroup() %d: got %v, expected %v", i, group, want)
+		}
+	}
+	if _, n, err := dec.GetGroup(); n != 0 || err != io.EOF {
+		t.Errorf("Expected EOF after three groups, got n = %d, err = %v", n, err)
+	}
+	// The rest of a partly read group comes first
+	dec = NewU32GroupVarintDecoder(bytes.NewReader(data))
+	dec.GetU32()
+	if group, n, err := dec.GetGroup(); n != 3 || err != nil || group != [4]uint32{testU32[1], testU32[2], testU32[3], 0} {
+		t.Errorf("GetGroup() after GetU32(): got %v, n = %d, err = %v", group, n, err)
+	}
+}
+
+func TestBase128EncoderMinBytes(t *testing.T) {
+	for _, w := range []io.Writer{&bytes.Buffer{}, writerOnly{&bytes.Buffer{}}} {
+		enc := NewU32Base128Encoder(w).MinBytes(3)
+		if n, err := enc.PutU32(1); n != 3 || err != nil {
+			t.Errorf("PutU32(1) with MinBytes(3) to %T: got n = %d, err = %v, expected 3 bytes", w, n, err)
+		}
+	}
+	var buf bytes.Buffer
+	enc := NewU32Base128Encoder(&buf).MinBytes(3)
+	enc.PutU32(1)
+	if !bytes.Equal(buf.Bytes(), []byte{0x81, 0x80, 0x00}) {
+		t.Errorf("PutU32(1) with MinBytes(3): got %x, expected 818000", buf.Bytes())
+	}
+	// Values already that long are unchanged, and every write path pads
+	enc.PutU32s([]uint32{2, 1 << 20, 1 << 31})
+	enc.PutU32Func(2, func(i int) uint32 { return uint32(i) })
+	enc.PutU64(1 << 40)
+	expected := []uint64{1, 2, 1 << 20, 1 << 31, 0, 1, 1 << 40}
+	lengths := []int{3, 3, 3, 5, 3, 3, 6}
+	if total := 3*5 + 5 + 6; buf.Len() != total {
+		t.Errorf("Padded output is %d bytes, expected %d", buf.Len(), total)
+	}
+	data := buf.Bytes()
+	dec := NewU64Base128Decoder(bytes.NewReader(data))
+	for i, want := range expected {
+		if x, err := dec.GetU64(); x != want || err != nil {
+			t.Errorf("Got x = %d, err = %v, expected = %d at %d", x, err, want, i)
+		}
+		if _, n := binary.Uvarint(data); n != lengths[i] {
+			t.Errorf("Value %d took %d bytes, expected %d", i, n, lengths[i])
+		} else {
+			data = data[n:]
+		}
+	}
+}
+
+func TestU32GroupVarintDecoderSkip(t *testing.T) {
+	data := append([]byte{0xde, 0xad, 0xbe, 0xef}, encodeU32GroupVarint(testU32)...)
+	readers := []io.ByteReader{bytes.NewReader(data), bufio.NewReader(bytes.NewReader(data)), oneByteAtATime{bytes.NewReader(data)}}
+	for _, r := range readers {
+		dec, err := NewU32GroupVarintDecoderSkip(r, 4)
+		if err != nil {
+			t.Fatalf("NewU32GroupVarintDecoderSkip with %T: %s", r, err)
+		}
+		for i, expected := range testU32 {
+			if x, err := dec.GetU32(); x != expected || err != nil {
+				t.Errorf("Got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+			}
+		}
+		if _, err := dec.GetU32(); err != io.EOF {
+			t.Errorf("Expected EOF after %d values, got %v", len(testU32), err)
+		}
+	}
+	if _, err := NewU32GroupVarintDecoderSkip(bytes.NewReader(data[:3]), 4); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Input ending within the header: expected ErrTruncated, got %v", err)
+	}
+}
+
+func TestU32SliceEncoderShortBuffer(t *testing.T) {
+	// 127 takes one byte and 128 takes two, which leaves the cursor one byte short
+	buf := []byte{0xaa, 0xaa, 0xaa}
+	enc := NewU32SliceEncoder(buf)
+	for _, x := range []uint32{127, 127} {
+		if n, err := enc.PutU32(x); n != 1 || err != nil {
+			t.Fatalf("PutU32(%d): got n = %d, err = %v", x, n, err)
+		}
+	}
+	n, err := enc.PutU32(128)
+	if n != 0 || err != ErrShortBuffer {
+		t.Errorf("PutU32(128): got n = %d, err = %v, expected n = 0, err = %v", n, err, ErrShortBuffer)
+	}
+	if buf[2] != 0xaa || len(enc.Bytes()) != 2 {
+		t.Errorf("Failed PutU32 changed the buffer to %v with %d bytes used", buf, len(enc.Bytes()))
+	}
+	dec := NewU32Base128Decoder(bytes.NewReader(enc.Bytes()))
+	for i := 0; i < 2; i++ {
+		if x, err := dec.GetU32(); x != 127 || err != nil {
+			t.Errorf("GetU32() at %d: got x = %d, expected = 127, err = %v", i, x, err)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after 2 values, got %v", err)
+	}
+}
+
+func TestBase128GetU64s(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewU64Base128Encoder(&buf)
+	var data []uint64
+	for i := 0; i < 10; i++ {
+		for _, x := range testU64 {
+			enc.PutU64(x)
+			data = append(data, x)
+		}
+	}
+	enc.Close()
+	dec := NewU64Base128Decoder(&buf)
+	var decoded []uint64
+	window := make([]uint64, 16)
+	for {
+		n, err := dec.GetU64s(window)
+		decoded = append(decoded, window[:n]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("GetU64s after %d values: %s", len(decoded), err)
+		}
+		if n != len(window) {
+			t.Fatalf("GetU64s returned %d values without an error", n)
+		}
+	}
+	if len(decoded) != len(data) {
+		t.Fatalf("%d integers were decoded when %d were encoded", len(decoded), len(data))
+	}
+	for i, expected := range data {
+		if decoded[i] != expected {
+			t.Errorf("Got x = %d, expected = %d at %d", decoded[i], expected, i)
+		}
+	}
+	// An eleven byte varint is corrupt; the two values in front of it are still returned
+	corrupt := []byte{0x01, 0x02, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}
+	dec = NewU64Base128Decoder(bytes.NewReader(corrupt))
+	n, err := dec.GetU64s(window)
+	if n != 2 || err == nil || err == io.EOF || window[0] != 1 || window[1] != 2 {
+		t.Errorf("GetU64s on a corrupt stream: got n = %d, values = %v, err = %v", n, window[:n], err)
+	}
+}
+
+func TestChunkedU32Decoder(t *testing.T) {
+	data := testU32[:10]
+	var buf bytes.Buffer
+	enc := NewU32GroupVarintEncoder(&buf)
+	for _, x := range data {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	dec := NewChunkedU32Decoder(&buf, 4)
+	var decoded []uint32
+	for _, expected := range []int{4, 4, 2} {
+		chunk, err := dec.NextChunk()
+		if len(chunk) != expected || err != nil {
+			t.Fatalf("NextChunk(): got %d values, err = %v, expected %d values", len(chunk), err, expected)
+		}
+		decoded = append(decoded, chunk...)
+	}
+	if chunk, err := dec.NextChunk(); len(chunk) != 0 || err != io.EOF {
+		t.Errorf("NextChunk() at the end: got %v, err = %v, expected EOF", chunk, err)
+	}
+	for i, expected := range data {
+		if decoded[i] != expected {
+			t.Errorf("Got x = %d, expected = %d at %d", decoded[i], expected, i)
+		}
+	}
+	// Without a usable chunk size it still makes progress, a value at a time
+	dec = NewChunkedU32Decoder(bytes.NewReader(encodeU32GroupVarint(data)), 0)
+	for i, expected := range data {
+		if chunk, err := dec.NextChunk(); len(chunk) != 1 || chunk[0] != expected || err != nil {
+			t.Fatalf("NextChunk() with a zero chunk size: got %v, err = %v, expected = %d at %d", chunk, err, expected, i)
+		}
+	}
+}
+
+func TestU32GroupVarintEncoderSnapshot(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewU32GroupVarintEncoder(&buf)
+	for _, x := range fiveU32[:2] {
+		enc.PutU32(x)
+	}
+	// Persist the state as a restarting process would, then throw the encoder away
+	var saved bytes.Buffer
+	if err := gob.NewEncoder(&saved).Encode(enc.Snapshot()); err != nil {
+		t.Fatalf("Encoding the snapshot: %s", err)
+	}
+	var state EncoderState
+	if err := gob.NewDecoder(&saved).Decode(&state); err != nil {
+		t.Fatalf("Decoding the snapshot: %s", err)
+	}
+	enc = NewU32GroupVarintEncoderFromState(&buf, state)
+	for _, x := range fiveU32[2:] {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	dec := NewU32GroupVarintDecoder(&buf)
+	for i, expected := range fiveU32 {
+		x, err := dec.GetU32()
+		if x != expected || err != nil {
+			t.Errorf("GetU32() at %d: got x = %d, expected = %d, err = %v", i, x, expected, err)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after %d values, got %v", len(fiveU32), err)
+	}
+}
+
+func TestU32GroupVarintDecoderAfterCount(t *testing.T) {
+	var buf bytes.Buffer
+	NewU64Base128Encoder(&buf).PutU64(uint64(len(fiveU32)))
+	enc := NewU32GroupVarintEncoder(&buf)
+	for _, x := range fiveU32 {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	dec, count, err := NewU32GroupVarintDecoderAfterCount(&buf)
+	if count != uint64(len(fiveU32)) || err != nil {
+		t.Fatalf("NewU32GroupVarintDecoderAfterCount: got count = %d, err = %v, expected count = %d", count, err, len(fiveU32))
+	}
+	for i, expected := range fiveU32 {
+		x, err := dec.GetU32()
+		if x != expected || err != nil {
+			t.Errorf("GetU32() at %d: got x = %d, expected = %d, err = %v", i, x, expected, err)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after %d values, got %v", len(fiveU32), err)
+	}
+	if _, _, err := NewU32GroupVarintDecoderAfterCount(bytes.NewReader(nil)); err != io.EOF {
+		t.Errorf("Missing count: got err = %v, expected EOF", err)
+	}
+}
+
+func TestU32GroupVarintDecodeStats(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewU32GroupVarintEncoder(&buf)
+	for _, x := range fiveU32 {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	count, min, max, err := NewU32GroupVarintDecoder(&buf).DecodeStats()
+	if count != 5 || min != 42 || max != 4294967196 || err != nil {
+		t.Errorf("DecodeStats(): got count = %d, min = %d, max = %d, err = %v", count, min, max, err)
+	}
+	count, min, max, err = NewU32GroupVarintDecoder(bytes.NewReader(nil)).DecodeStats()
+	if count != 0 || min != 1<<32-1 || max != 0 || err != nil {
+		t.Errorf("DecodeStats() of an empty stream: got count = %d, min = %d, max = %d, err = %v", count, min, max, err)
+	}
+}
+
+func TestControlTable(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		sizeByte := byte(i)
+		// The original arithmetic from getGroup
+		expected := [4]uint8{
+			(sizeByte>>6)&3 + 1,
+			(sizeByte>>4)&3 + 1,
+			(sizeByte>>2)&3 + 1,
+			sizeByte&3 + 1,
+		}
+		if controlTable[sizeByte] != expected {
+			t.Errorf("controlTable[%#x]: got %v, expected %v", sizeByte, controlTable[sizeByte], expected)
+		}
+		total := expected[0] + expected[1] + expected[2] + expected[3]
+		if controlTableTotal[sizeByte] != total {
+			t.Errorf("controlTableTotal[%#x]: got %d, expected %d", sizeByte, controlTableTotal[sizeByte], total)
+		}
+	}
+}
+
+func TestU32GroupVarintDecodeN(t *testing.T) {
+	data := encodeU32GroupVarint(testU32)
+	xs, err := NewU32GroupVarintDecoder(bytes.NewReader(data)).DecodeN(len(testU32))
+	if err != nil || len(xs) != len(testU32) || cap(xs) != len(testU32) {
+		t.Fatalf("DecodeN: got %d values (cap %d), err = %v, expected %d", len(xs), cap(xs), err, len(testU32))
+	}
+	for i, expected := range testU32 {
+		if xs[i] != expected {
+			t.Errorf("Got x = %d, expected = %d at %d", xs[i], expected, i)
+		}
+	}
+	xs, err = NewU32GroupVarintDecoder(bytes.NewReader(data)).DecodeN(len(testU32) + 1)
+	if !errors.Is(err, ErrTruncated) || len(xs) != len(testU32) {
+		t.Errorf("Too few: got %d values, err = %v, expected %d values and ErrTruncated", len(xs), err, len(testU32))
+	}
+	xs, err = NewU32GroupVarintDecoder(bytes.NewReader(data)).DecodeN(len(testU32) - 1)
+	if !errors.Is(err, ErrValueLimitExceeded) || len(xs) != len(testU32)-1 {
+		t.Errorf("Too many: got %d values, err = %v, expected %d values and ErrValueLimitExceeded", len(xs), err, len(testU32)-1)
+	}
+	if xs, err := NewU32GroupVarintDecoder(bytes.NewReader(nil)).DecodeN(0); err != nil || len(xs) != 0 {
+		t.Errorf("Empty: got %d values, err = %v", len(xs), err)
+	}
+}
+
+// oneByteAtATime hides the reader's type from the decoder's fast paths
+type oneByteAtATime struct{ r io.ByteReader }
+
+func (o oneByteAtATime) ReadByte() (byte, error) { return o.r.ReadByte() }
+
+func TestU32GroupVarintZeroSizeByte(t *testing.T) {
+	// Runs of zeros, groups of single byte values that aren't zero, and a partial final group with size byte zero
+	var values []uint32
+	for i := 0; i < 64; i++ {
+		values = append(values, 0)
+	}
+	values = append(values, 1, 255, 0, 7, 1<<20, 0, 0, 0, 0, 9, 3)
+	data := encodeU32GroupVarint(values)
+	readers := map[string]func() io.ByteReader{
+		"bytes.Reader": func() io.ByteReader { return bytes.NewReader(data) },
+		"bufio.Reader": func() io.ByteReader { return bufio.NewReaderSize(bytes.NewReader(data), 16) },
+		"ByteReader":   func() io.ByteReader { return oneByteAtATime{bytes.NewReader(data)} },
+	}
+	for name, r := range readers {
+		dec := NewU32GroupVarintDecoder(r())
+		for i, expected := range values {
+			if x, err := dec.GetU32(); x != expected || err != nil {
+				t.Fatalf("%s: got x = %d, err = %v, expected = %d at %d", name, x, err, expected, i)
+			}
+		}
+		if _, err := dec.GetU32(); err != io.EOF {
+			t.Errorf("%s: expected EOF at the end, got %v", name, err)
+		}
+	}
+	if decoded := decodeU32GroupVarint(t, data); len(decoded) != len(values) {
+		t.Fatalf("Slice decoder: %d integers were decoded when %d were encoded", len(decoded), len(values))
+	} else {
+		for i := range values {
+			if decoded[i] != values[i] {
+				t.Errorf("Slice decoder: got x = %d, expected = %d at %d", decoded[i], values[i], i)
+			}
+		}
+	}
+	// Buffered still knows where each value ends inside a fast path group
+	slice := NewU32GroupVarintSliceDecoder(data)
+	slice.GetU32()
+	if len(slice.Buffered()) != len(data)-2 {
+		t.Errorf("Buffered() after one value has %d bytes, expected %d", len(slice.Buffered()), len(data)-2)
+	}
+}
+
+func BenchmarkGroupVarintZeros(b *testing.B) {
+	data := encodeU32GroupVarint(make([]uint32, 4096))
+	b.Run("Stream", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			dec := NewU32GroupVarintDecoder(bytes.NewReader(data))
+			for {
+				if _, err := dec.GetU32(); err != nil {
+					break
+				}
+			}
+		}
+	})
+	b.Run("Slice", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			dec := NewU32GroupVarintSliceDecoder(data)
+			for {
+				if _, err := dec.GetU32(); err != nil {
+					break
+				}
+			}
+		}
+	})
+}
+
+func TestPutU32Func(t *testing.T) {
+	double := func(i int) uint32 { return uint32(i * 2) }
+	var base128, group bytes.Buffer
+	benc := NewU32Base128Encoder(writerOnly{&base128})
+	if n, err := benc.PutU32Func(100, double); err != nil || n != base128.Len() {
+		t.Fatalf("Base128 PutU32Func: got n = %d, err = %v, with %d bytes written", n, err, base128.Len())
+	}
+	benc.Close()
+	genc := NewU32GroupVarintEncoder(&group)
+	if _, err := genc.PutU32Func(100, double); err != nil {
+		t.Fatalf("GroupVarint PutU32Func: %s", err)
+	}
+	genc.Close()
+	decoders := map[string]U32VarintDecoder{
+		"Base128":     NewU32Base128Decoder(&base128),
+		"GroupVarint": NewU32GroupVarintDecoder(&group),
+	}
+	for name, dec := range decoders {
+		for i := 0; i < 100; i++ {
+			if x, err := dec.GetU32(); x != uint32(i*2) || err != nil {
+				t.Fatalf("%s: got x = %d, err = %v, expected = %d", name, x, err, i*2)
+			}
+		}
+		if _, err := dec.GetU32(); err != io.EOF {
+			t.Errorf("%s: expected EOF after 100 values, got %v", name, err)
+		}
+	}
+	// More output than one batch is written in several goes, and a short write stops them
+	var big bytes.Buffer
+	benc = NewU32Base128Encoder(writerOnly{&big})
+	if n, err := benc.PutU32Func(100000, func(i int) uint32 { return 1 << 28 }); err != nil || n != 500000 || big.Len() != 500000 {
+		t.Errorf("Large PutU32Func: got n = %d, err = %v, with %d bytes written, expected 500000", n, err, big.Len())
+	}
+	short := &shortWriter{limit: 70000}
+	benc = NewU32Base128Encoder(short)
+	if n, err := benc.PutU32Func(100000, func(i int) uint32 { return 1 << 28 }); err != io.ErrShortWrite || n != 70000 {
+		t.Errorf("Short write: got n = %d, err = %v, expected 70000 and io.ErrShortWrite", n, err)
+	}
+}
+
+func TestU32GroupVarintDebugGroup(t *testing.T) {
+	dec := NewU32GroupVarintDecoder(bytes.NewReader(encodeU32GroupVarint(fiveU32)))
+	if _, pos, capacity, finished := dec.DebugGroup(); pos != 4 || capacity != 4 || finished {
+		t.Errorf("Fresh decoder: got pos = %d, capacity = %d, finished = %t", pos, capacity, finished)
+	}
+	dec.GetU32()
+	dec.GetU32()
+	group, pos, capacity, finished := dec.DebugGroup()
+	if pos != 2 || capacity != 4 || finished {
+		t.Errorf("After two values: got pos = %d, capacity = %d, finished = %t, expected 2, 4, false", pos, capacity, finished)
+	}
+	if group != [4]uint32(fiveU32[:4]) {
+		t.Errorf("Got group %v, expected %v", group, fiveU32[:4])
+	}
+	// The fifth value is a partial group of one, which marks the stream as finished
+	for i := 0; i < 3; i++ {
+		dec.GetU32()
+	}
+	if group, pos, capacity, finished := dec.DebugGroup(); group[0] != fiveU32[4] || pos != 1 || capacity != 1 || !finished {
+		t.Errorf("Partial group: got group %v, pos = %d, capacity = %d, finished = %t", group, pos, capacity, finished)
+	}
+}
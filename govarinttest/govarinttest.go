@@ -0,0 +1,109 @@
+// Package govarinttest helps users compare the govarint codecs on their own data.
+package govarinttest
+
+import "bytes"
+import "fmt"
+import "io"
+import "testing"
+
+import "github.com/couchbasedeps/govarint"
+
+type codec struct {
+	name      string
+	newEncode func(w io.Writer) govarint.U32VarintEncoder
+	newDecode func(r io.ByteReader) govarint.U32VarintDecoder
+}
+
+var codecs = []codec{
+	{
+		"Base128",
+		func(w io.Writer) govarint.U32VarintEncoder { return govarint.NewU32Base128Encoder(w) },
+		func(r io.ByteReader) govarint.U32VarintDecoder { return govarint.NewU32Base128Decoder(r) },
+	},
+	{
+		"GroupVarint",
+		func(w io.Writer) govarint.U32VarintEncoder { return govarint.NewU32GroupVarintEncoder(w) },
+		func(r io.ByteReader) govarint.U32VarintDecoder { return govarint.NewU32GroupVarintDecoder(r) },
+	},
+	{
+		"DeltaBase128",
+		func(w io.Writer) govarint.U32VarintEncoder {
+			return govarint.NewU32DeltaEncoder(govarint.NewU32Base128Encoder(w))
+		},
+		func(r io.ByteReader) govarint.U32VarintDecoder {
+			return govarint.NewU32DeltaDecoder(govarint.NewU32Base128Decoder(r))
+		},
+	},
+	{
+		"DeltaGroupVarint",
+		func(w io.Writer) govarint.U32VarintEncoder {
+			return govarint.NewU32DeltaEncoder(govarint.NewU32GroupVarintEncoder(w))
+		},
+		func(r io.ByteReader) govarint.U32VarintDecoder {
+			return govarint.NewU32DeltaDecoder(govarint.NewU32GroupVarintDecoder(r))
+		},
+	},
+}
+
+func encode(c codec, xs []uint32) []byte {
+	var buf bytes.Buffer
+	enc := c.newEncode(&buf)
+	for _, x := range xs {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	return buf.Bytes()
+}
+
+// BenchmarkCodecs runs an encode and a decode sub-benchmark for every built-in codec over xs.
+// Each reports the encoded size as a bytes/value metric alongside the timings.
+func BenchmarkCodecs(b *testing.B, xs []uint32) {
+	if len(xs) == 0 {
+		b.Fatalf("BenchmarkCodecs needs at least one value")
+	}
+	for _, c := range codecs {
+		data := encode(c, xs)
+		bytesPerValue := float64(len(data)) / float64(len(xs))
+		b.Run(c.name+"/Encode", func(b *testing.B) {
+			b.SetBytes(int64(4 * len(xs)))
+			var buf bytes.Buffer
+			for i := 0; i < b.N; i++ {
+				encodeInto(c, &buf, xs)
+			}
+			b.ReportMetric(bytesPerValue, "bytes/value")
+		})
+		b.Run(c.name+"/Decode", func(b *testing.B) {
+			b.SetBytes(int64(4 * len(xs)))
+			r := bytes.NewReader(data)
+			for i := 0; i < b.N; i++ {
+				if err := decodeFrom(c, r, data, xs); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(bytesPerValue, "bytes/value")
+		})
+	}
+}
+
+// encodeInto is one iteration of an encode sub-benchmark, reusing buf
+func encodeInto(c codec, buf *bytes.Buffer, xs []uint32) {
+	buf.Reset()
+	enc := c.newEncode(buf)
+	for _, x := range xs {
+		enc.PutU32(x)
+	}
+	enc.Close()
+}
+
+// decodeFrom is one iteration of a decode sub-benchmark, reusing r, and fails if data doesn't decode to xs
+func decodeFrom(c codec, r *bytes.Reader, data []byte, xs []uint32) error {
+	r.Reset(data)
+	dec := c.newDecode(r)
+	for j, expected := range xs {
+		x, err := dec.GetU32()
+		if x != expected || err != nil {
+			return fmt.Errorf("%s decoded x = %d, expected = %d at %d, err = %v", c.name, x, expected, j, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package govarinttest
+
+import "bytes"
+import "testing"
+
+var sample = []uint32{1, 3, 7, 200, 1000, 70000, 70001, 1 << 30, 1<<32 - 1}
+
+func TestBenchmarkCodecs(t *testing.T) {
+	// A single iteration of each sub-benchmark is enough to check that they all run
+	for _, c := range codecs {
+		var buf bytes.Buffer
+		encodeInto(c, &buf, sample)
+		data := buf.Bytes()
+		if err := decodeFrom(c, bytes.NewReader(data), data, sample); err != nil {
+			t.Errorf("BenchmarkCodecs failed on %v: %s", sample, err)
+		}
+	}
+}
+
+func BenchmarkSample(b *testing.B) {
+	BenchmarkCodecs(b, sample)
+}
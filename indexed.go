@@ -0,0 +1,64 @@
+package govarint
+
+import "encoding/binary"
+import "fmt"
+import "io"
+
+// IndexedU32Encoder writes group varint along with an index of where every stride'th group starts.
+// The index is a little endian int64 byte offset per indexed group, written as the data is,
+// and once parsed with ParseU32GroupIndex it can be passed to the slice decoder's GetAt with a
+// value stride of 4*stride.
+type IndexedU32Encoder struct {
+	enc    *U32GroupVarintEncoder
+	index  io.Writer
+	stride int
+	off    int64
+	count  int
+}
+
+// NewIndexedU32Encoder writes values to data and the offsets of groups 0, stride, 2*stride, ... to index
+func NewIndexedU32Encoder(data io.Writer, index io.Writer, stride int) *IndexedU32Encoder {
+	if stride < 1 {
+		stride = 1
+	}
+	return &IndexedU32Encoder{enc: NewU32GroupVarintEncoder(data), index: index, stride: stride}
+}
+
+func (b *IndexedU32Encoder) PutU32(x uint32) (int, error) {
+	if b.enc.closed {
+		return 0, ErrClosed
+	}
+	if b.count%(4*b.stride) == 0 {
+		var entry [8]byte
+		binary.LittleEndian.PutUint64(entry[:], uint64(b.off))
+		if _, err := b.index.Write(entry[:]); err != nil {
+			return 0, err
+		}
+	}
+	n, err := b.enc.PutU32(x)
+	b.off += int64(n)
+	if err != nil {
+		return n, err
+	}
+	b.count += 1
+	return n, nil
+}
+
+// Close writes any partial final group. The index is complete as soon as the values are written.
+func (b *IndexedU32Encoder) Close() error {
+	n, err := b.enc.finish()
+	b.off += int64(n)
+	return err
+}
+
+// ParseU32GroupIndex reads the group offsets out of an index written by an IndexedU32Encoder
+func ParseU32GroupIndex(index []byte) ([]int64, error) {
+	if len(index)%8 != 0 {
+		return nil, fmt.Errorf("govarint: group index length %d is not a multiple of 8: %w", len(index), ErrTruncated)
+	}
+	var idx []int64
+	for off := 0; off < len(index); off += 8 {
+		idx = append(idx, int64(binary.LittleEndian.Uint64(index[off:])))
+	}
+	return idx, nil
+}
@@ -0,0 +1,41 @@
+package govarint
+
+import "bytes"
+import "testing"
+
+func TestIndexedU32Encoder(t *testing.T) {
+	values := randomU32s(1000)
+	var data, index bytes.Buffer
+	enc := NewIndexedU32Encoder(&data, &index, 8)
+	for _, x := range values {
+		if _, err := enc.PutU32(x); err != nil {
+			t.Fatalf("PutU32: %s", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	idx, err := ParseU32GroupIndex(index.Bytes())
+	if err != nil {
+		t.Fatalf("ParseU32GroupIndex: %s", err)
+	}
+	// 250 groups indexed every 8 is groups 0, 8, ..., 248, the same as building the index afterwards
+	expected := buildGroupIndex(data.Bytes(), 32)
+	if len(idx) != len(expected) {
+		t.Fatalf("Index has %d entries, expected %d", len(idx), len(expected))
+	}
+	for i := range expected {
+		if idx[i] != expected[i] {
+			t.Errorf("Got offset %d, expected %d at entry %d", idx[i], expected[i], i)
+		}
+	}
+	dec := NewU32GroupVarintSliceDecoder(data.Bytes())
+	for _, i := range []int{0, 500, 517, 999} {
+		if x, err := dec.GetAt(i, idx, 32); x != values[i] || err != nil {
+			t.Errorf("GetAt(%d): got x = %d, err = %v, expected = %d", i, x, err, values[i])
+		}
+	}
+	if _, err := ParseU32GroupIndex(index.Bytes()[:5]); err == nil {
+		t.Errorf("ParseU32GroupIndex of a cut off entry should fail")
+	}
+}
@@ -0,0 +1,42 @@
+package govarint
+
+import "io"
+import "iter"
+
+// All returns an iterator over the rest of the stream, for use as
+//
+//	for x, err := range dec.All() {
+//
+// The iteration simply ends at EOF. Any other error is yielded once, with a zero value, and ends it.
+func (b *U32GroupVarintDecoder) All() iter.Seq2[uint32, error] {
+	return func(yield func(uint32, error) bool) {
+		for {
+			x, err := b.GetU32()
+			if err == io.EOF {
+				return
+			}
+			if !yield(x, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// DecodeU32ToChan decodes the group varint stream r, sending each value on ch, for feeding a pipeline
+// from a decoding goroutine. The function takes ownership of ch: it closes ch once the stream ends,
+// whether cleanly or with an error, so consumers ranging over ch always finish. The caller must not
+// close or send on ch itself. Reaching EOF returns nil, and any other error is returned after closing.
+func DecodeU32ToChan(r io.ByteReader, ch chan<- uint32) error {
+	defer close(ch)
+	dec := NewU32GroupVarintDecoder(r)
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		ch <- x
+	}
+}
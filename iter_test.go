@@ -0,0 +1,77 @@
+package govarint
+
+import "bytes"
+import "errors"
+import "testing"
+
+func TestU32GroupVarintDecoderAll(t *testing.T) {
+	var decoded []uint32
+	for x, err := range NewU32GroupVarintDecoder(bytes.NewReader(encodeU32GroupVarint(fiveU32))).All() {
+		if err != nil {
+			t.Fatalf("All() yielded err = %v", err)
+		}
+		decoded = append(decoded, x)
+	}
+	if len(decoded) != len(fiveU32) {
+		t.Fatalf("%d integers were decoded when %d were encoded", len(decoded), len(fiveU32))
+	}
+	for i, expected := range fiveU32 {
+		if decoded[i] != expected {
+			t.Errorf("Got x = %d, expected = %d at %d", decoded[i], expected, i)
+		}
+	}
+	// Cutting off the last byte truncates the fifth value, which is two bytes long
+	data := encodeU32GroupVarint(fiveU32)
+	data = data[:len(data)-1]
+	values, errs := 0, 0
+	for _, err := range NewU32GroupVarintDecoder(bytes.NewReader(data)).All() {
+		if err != nil {
+			errs += 1
+			if !errors.Is(err, ErrTruncated) {
+				t.Errorf("All() yielded err = %v, expected ErrTruncated", err)
+			}
+			continue
+		}
+		values += 1
+	}
+	if values != 4 || errs != 1 {
+		t.Errorf("All() on a truncated stream yielded %d values and %d errors, expected 4 values and 1 error", values, errs)
+	}
+}
+
+func TestDecodeU32ToChan(t *testing.T) {
+	data := randomU32s(1001)
+	ch := make(chan uint32, 16)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- DecodeU32ToChan(bytes.NewReader(encodeU32GroupVarint(data)), ch)
+	}()
+	var decoded []uint32
+	for x := range ch {
+		decoded = append(decoded, x)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("DecodeU32ToChan: %s", err)
+	}
+	if len(decoded) != len(data) {
+		t.Fatalf("%d integers were received when %d were encoded", len(decoded), len(data))
+	}
+	for i, expected := range data {
+		if decoded[i] != expected {
+			t.Errorf("Got x = %d, expected = %d at %d", decoded[i], expected, i)
+		}
+	}
+	// A truncated stream still closes the channel, after the values before the bad group
+	encoded := encodeU32GroupVarint(fiveU32)
+	ch = make(chan uint32)
+	go func() {
+		errc <- DecodeU32ToChan(bytes.NewReader(encoded[:len(encoded)-1]), ch)
+	}()
+	received := 0
+	for range ch {
+		received += 1
+	}
+	if err := <-errc; !errors.Is(err, ErrTruncated) || received != 4 {
+		t.Errorf("Got %d values, err = %v, expected 4 values and ErrTruncated", received, err)
+	}
+}
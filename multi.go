@@ -0,0 +1,107 @@
+package govarint
+
+import "fmt"
+import "io"
+
+// MultiU32Decoder reads several group varint streams that were written one after another.
+// A stream whose length isn't a multiple of four ends in a partial group, and only the
+// value counts tell where such a stream stops and the next one begins.
+type MultiU32Decoder struct {
+	r         io.ByteReader
+	counts    []int
+	remaining int
+	group     [4]uint32
+	pos       int
+	capacity  int
+}
+
+// NewMultiU32Decoder decodes counts[0] values from the first stream in r, counts[1] from the next, and so on.
+// A negative count is an error from GetU32 on reaching it.
+func NewMultiU32Decoder(r io.ByteReader, counts []int) *MultiU32Decoder {
+	return &MultiU32Decoder{r: r, counts: counts}
+}
+
+func (b *MultiU32Decoder) getGroup() error {
+	// Move on to the next stream that has any values in it
+	for b.remaining == 0 {
+		if len(b.counts) == 0 {
+			return io.EOF
+		}
+		if b.counts[0] < 0 {
+			err := fmt.Errorf("govarint: negative value count %d", b.counts[0])
+			b.counts = nil
+			return err
+		}
+		b.remaining = b.counts[0]
+		b.counts = b.counts[1:]
+	}
+	n := b.remaining
+	if n > 4 {
+		n = 4
+	}
+	sizeByte, err := b.r.ReadByte()
+	if err == nil {
+		// Read only as many values as the stream has left, the rest belongs to the next stream
+		for index := 0; index < n && err == nil; index++ {
+			size := int(controlTable[sizeByte][index])
+			x := uint32(0)
+			for i := 0; i < size && err == nil; i++ {
+				var y byte
+				y, err = b.r.ReadByte()
+				x = x<<8 | uint32(y)
+			}
+			b.group[index] = x
+		}
+	}
+	if err != nil {
+		// The counts promised more values than the input holds
+		if err == io.EOF {
+			err = errUnexpectedEOF
+		}
+		b.counts = nil
+		b.remaining = 0
+		return err
+	}
+	b.remaining -= n
+	b.pos = 0
+	b.capacity = n
+	return nil
+}
+
+func (b *MultiU32Decoder) GetU32() (uint32, error) {
+	if b.pos == b.capacity {
+		err := b.getGroup()
+		if err != nil {
+			return 0, err
+		}
+	}
+	b.pos += 1
+	return b.group[b.pos-1], nil
+}
+
+// NewU32PaddedGroupVarintDecoder reads the group varint that an encoder with PadOnClose wrote to r,
+// taking the value count it wrote separately from count and dropping the padding after that many values
+func NewU32PaddedGroupVarintDecoder(r io.ByteReader, count io.ByteReader) (*MultiU32Decoder, error) {
+	n, err := readUvarint(count)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(int(^uint(0)>>1)) {
+		return nil, ErrOverflow
+	}
+	return NewMultiU32Decoder(r, []int{int(n)}), nil
+}
+
+// NewU32GroupVarintVarLenDecoder reads a Base128 value count from r and decodes exactly that many
+// group varint values after it, so a single array describes its own length. Nothing past the final
+// value is read, and if the input ends before count values GetU32 fails with io.ErrUnexpectedEOF.
+func NewU32GroupVarintVarLenDecoder(r io.ByteReader) (*MultiU32Decoder, error) {
+	count, err := readUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(int(^uint(0)>>1)) {
+		return nil, ErrOverflow
+	}
+	return NewMultiU32Decoder(r, []int{int(count)}), nil
+}
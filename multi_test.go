@@ -0,0 +1,123 @@
+package govarint
+
+import "bytes"
+import "errors"
+import "io"
+import "testing"
+
+func TestMultiU32Decoder(t *testing.T) {
+	// Partial, empty and full final groups all sit on a stream boundary
+	segments := [][]uint32{fiveU32, {}, testU32, fourU32, fiveU32[:1]}
+	var buf bytes.Buffer
+	var counts []int
+	var expected []uint32
+	for _, segment := range segments {
+		buf.Write(encodeU32GroupVarint(segment))
+		counts = append(counts, len(segment))
+		expected = append(expected, segment...)
+	}
+	data := buf.Bytes()
+	dec := NewMultiU32Decoder(bytes.NewReader(data), counts)
+	for i, x := range expected {
+		got, err := dec.GetU32()
+		if got != x || err != nil {
+			t.Errorf("GetU32() at %d: got x = %d, expected = %d, err = %v", i, got, x, err)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after %d values, got %v", len(expected), err)
+	}
+	// Claiming one more value than is there is a truncated input
+	counts[len(counts)-1] += 1
+	dec = NewMultiU32Decoder(bytes.NewReader(data), counts)
+	var err error
+	for err == nil {
+		_, err = dec.GetU32()
+	}
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("Too large a count: got err = %v, expected ErrTruncated", err)
+	}
+	// A negative count fails rather than going out of range, and ends the stream
+	dec = NewMultiU32Decoder(bytes.NewReader(encodeU32GroupVarint(fiveU32)), []int{2, -1, 3})
+	for i, x := range fiveU32[:2] {
+		if got, err := dec.GetU32(); got != x || err != nil {
+			t.Errorf("GetU32() at %d: got x = %d, expected = %d, err = %v", i, got, x, err)
+		}
+	}
+	if _, err := dec.GetU32(); err == nil || err == io.EOF {
+		t.Errorf("Negative count: got err = %v, expected an error", err)
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after a negative count, got %v", err)
+	}
+}
+
+func TestU32GroupVarintVarLenDecoder(t *testing.T) {
+	for _, count := range []int{0, 3, 4, 5} {
+		values := randomU32s(count)
+		data := append([]byte{byte(count)}, encodeU32GroupVarint(values)...)
+		// A byte belonging to whatever follows the array
+		r := bytes.NewReader(append(data, 0xab))
+		dec, err := NewU32GroupVarintVarLenDecoder(r)
+		if err != nil {
+			t.Fatalf("Count %d: NewU32GroupVarintVarLenDecoder: %s", count, err)
+		}
+		for i, expected := range values {
+			if x, err := dec.GetU32(); x != expected || err != nil {
+				t.Errorf("Count %d: got x = %d, err = %v, expected = %d at %d", count, x, err, expected, i)
+			}
+		}
+		if _, err := dec.GetU32(); err != io.EOF {
+			t.Errorf("Count %d: expected EOF after the counted values, got %v", count, err)
+		}
+		if next, err := r.ReadByte(); next != 0xab || err != nil {
+			t.Errorf("Count %d: the decoder read past the array, next byte is %x, err = %v", count, next, err)
+		}
+		// Claiming one value more than is there runs out of input, failing the group it falls in
+		data[0] = byte(count + 1)
+		dec, _ = NewU32GroupVarintVarLenDecoder(bytes.NewReader(data))
+		for err == nil {
+			_, err = dec.GetU32()
+		}
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("Count %d + 1: expected io.ErrUnexpectedEOF, got %v", count, err)
+		}
+	}
+}
+
+func TestU32PaddedGroupVarintDecoder(t *testing.T) {
+	data := []uint32{1, 300, 2, 70000, 3, 4}
+	var buf, count bytes.Buffer
+	enc := NewU32GroupVarintEncoderWithMetrics(&buf)
+	enc.PadOnClose = true
+	enc.PadFill = 7
+	enc.PadCountWriter = &count
+	for _, x := range data {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	// The last group is 3, 4, 7, 7 rather than a partial group of two
+	raw := buf.Bytes()
+	offsets := groupVarintOffsets(raw)
+	if len(offsets) != 2 || offsets[1]+groupVarintGroupLen(raw[offsets[1]]) != len(raw) {
+		t.Fatalf("Padded output %x isn't two full groups", raw)
+	}
+	if !bytes.Equal(raw[offsets[1]:], []byte{0, 3, 4, 7, 7}) {
+		t.Errorf("Got last group %x, expected 0003040707", raw[offsets[1]:])
+	}
+	if values := enc.Metrics().Values; values != uint64(len(data)) {
+		t.Errorf("Metrics count %d values, expected %d", values, len(data))
+	}
+	dec, err := NewU32PaddedGroupVarintDecoder(bytes.NewReader(raw), &count)
+	if err != nil {
+		t.Fatalf("NewU32PaddedGroupVarintDecoder: %s", err)
+	}
+	for i, expected := range data {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Errorf("Got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after %d values, got %v", len(data), err)
+	}
+}
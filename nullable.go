@@ -0,0 +1,105 @@
+package govarint
+
+import "bytes"
+import "encoding/binary"
+import "io"
+
+// NullableU32Encoder writes a column of optional values in two segments, Arrow style:
+//
+//	count (base128) | validity bitmap, one bit per position, least significant bit first | present values (group varint)
+//
+// Nulls take up a bit in the bitmap and nothing in the values segment.
+// Everything is buffered until Close since the bitmap has to come first.
+type NullableU32Encoder struct {
+	w      io.Writer
+	count  int
+	bitmap []byte
+	values bytes.Buffer
+	enc    *U32GroupVarintEncoder
+}
+
+func NewNullableU32Encoder(w io.Writer) *NullableU32Encoder {
+	b := &NullableU32Encoder{w: w}
+	b.enc = NewU32GroupVarintEncoder(&b.values)
+	return b
+}
+
+func (b *NullableU32Encoder) put(valid bool) {
+	if b.count%8 == 0 {
+		b.bitmap = append(b.bitmap, 0)
+	}
+	if valid {
+		b.bitmap[b.count/8] |= 1 << uint(b.count%8)
+	}
+	b.count += 1
+}
+
+func (b *NullableU32Encoder) PutNull() {
+	b.put(false)
+}
+
+func (b *NullableU32Encoder) PutU32(x uint32) {
+	b.put(true)
+	b.enc.PutU32(x)
+}
+
+// Close writes out both segments
+func (b *NullableU32Encoder) Close() error {
+	b.enc.Close()
+	header := make([]byte, binary.MaxVarintLen64)
+	header = header[:binary.PutUvarint(header, uint64(b.count))]
+	for _, segment := range [][]byte{header, b.bitmap, b.values.Bytes()} {
+		if _, err := b.w.Write(segment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+///
+
+type NullableU32Decoder struct {
+	count  int
+	pos    int
+	bitmap []byte
+	dec    *U32GroupVarintDecoder
+}
+
+// NewNullableU32Decoder reads the count and validity bitmap up front, leaving r at the values segment
+func NewNullableU32Decoder(r io.ByteReader) (*NullableU32Decoder, error) {
+	count, err := readUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	// The bitmap is grown as it's read so a corrupt count can't force a huge allocation
+	var bitmap []byte
+	for i := uint64(0); i*8 < count; i++ {
+		x, err := r.ReadByte()
+		if err == io.EOF {
+			return nil, errUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		bitmap = append(bitmap, x)
+	}
+	return &NullableU32Decoder{count: int(count), bitmap: bitmap, dec: NewU32GroupVarintDecoder(r)}, nil
+}
+
+// Next returns the value at the next position, or valid == false if that position is null
+func (b *NullableU32Decoder) Next() (value uint32, valid bool, err error) {
+	if b.pos == b.count {
+		return 0, false, io.EOF
+	}
+	valid = b.bitmap[b.pos/8]&(1<<uint(b.pos%8)) != 0
+	b.pos += 1
+	if !valid {
+		return 0, false, nil
+	}
+	value, err = b.dec.GetU32()
+	if err == io.EOF {
+		// The bitmap promised a value the values segment doesn't have
+		err = errUnexpectedEOF
+	}
+	return value, err == nil, err
+}
@@ -0,0 +1,41 @@
+package govarint
+
+import "bytes"
+import "io"
+import "testing"
+
+func TestNullableU32RoundTrip(t *testing.T) {
+	// A nil entry stands for a null
+	one, big, zero := uint32(1), uint32(1<<31), uint32(0)
+	column := []*uint32{nil, &one, nil, nil, &big, &zero, nil, &one, &one, nil, &big}
+	var buf bytes.Buffer
+	enc := NewNullableU32Encoder(&buf)
+	for _, x := range column {
+		if x == nil {
+			enc.PutNull()
+		} else {
+			enc.PutU32(*x)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	dec, err := NewNullableU32Decoder(&buf)
+	if err != nil {
+		t.Fatalf("NewNullableU32Decoder: %s", err)
+	}
+	for i, expected := range column {
+		x, valid, err := dec.Next()
+		if err != nil {
+			t.Fatalf("Next() at %d: %s", i, err)
+		}
+		if valid != (expected != nil) {
+			t.Errorf("Next() at %d: got valid = %t, expected = %t", i, valid, expected != nil)
+		} else if valid && x != *expected {
+			t.Errorf("Next() at %d: got x = %d, expected = %d", i, x, *expected)
+		}
+	}
+	if _, _, err := dec.Next(); err != io.EOF {
+		t.Errorf("Expected EOF after %d positions, got %v", len(column), err)
+	}
+}
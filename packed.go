@@ -0,0 +1,53 @@
+package govarint
+
+import "io"
+
+type limitedByteReader struct {
+	r io.ByteReader
+	n uint64
+}
+
+func (l *limitedByteReader) ReadByte() (byte, error) {
+	if l.n == 0 {
+		return 0, io.EOF
+	}
+	l.n -= 1
+	return l.r.ReadByte()
+}
+
+// DecodePackedVarints reads a protobuf packed repeated varint field, without its tag:
+// a Base128 byte length followed by that many bytes of concatenated Base128 varints.
+// Nothing past the declared length is read from r. An empty r holds no field, giving nil and no error.
+func DecodePackedVarints(r io.ByteReader) ([]uint64, error) {
+	return DecodePackedVarintsLimit(r, -1)
+}
+
+// DecodePackedVarintsLimit is DecodePackedVarints for untrusted input, returning at most maxValues values.
+// If the field holds more, the first maxValues are returned along with ErrValueLimitExceeded.
+// A negative maxValues means no limit.
+func DecodePackedVarintsLimit(r io.ByteReader, maxValues int) ([]uint64, error) {
+	length, err := readUvarint(r)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lr := &limitedByteReader{r: r, n: length}
+	var values []uint64
+	for lr.n > 0 {
+		if len(values) == maxValues {
+			return values, ErrValueLimitExceeded
+		}
+		v, err := readUvarint(lr)
+		if err != nil {
+			// Either the field or the stream ended partway through a varint
+			if err == io.EOF {
+				err = errUnexpectedEOF
+			}
+			return values, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
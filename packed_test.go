@@ -0,0 +1,52 @@
+package govarint
+
+import "bytes"
+import "errors"
+import "io"
+import "testing"
+
+func TestDecodePackedVarints(t *testing.T) {
+	// Packed field payload [3, 270, 86942] from the protobuf encoding guide, after its tag byte
+	// The 0x01 after the field must be left for the next reader
+	field := []byte{0x06, 0x03, 0x8E, 0x02, 0x9E, 0xA7, 0x05, 0x01}
+	expected := []uint64{3, 270, 86942}
+	r := bytes.NewReader(field)
+	values, err := DecodePackedVarints(r)
+	if err != nil {
+		t.Fatalf("DecodePackedVarints: %s", err)
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("%d integers were decoded when %d were expected", len(values), len(expected))
+	}
+	for i := range expected {
+		if values[i] != expected[i] {
+			t.Errorf("Got x = %d, expected = %d at %d", values[i], expected[i], i)
+		}
+	}
+	if r.Len() != 1 {
+		t.Errorf("%d bytes remain after the packed field, expected 1", r.Len())
+	}
+	// A length that splits the final varint is an error, as is running out of stream
+	for _, bad := range [][]byte{
+		{0x05, 0x03, 0x8E, 0x02, 0x9E, 0xA7, 0x05},
+		{0x06, 0x03, 0x8E, 0x02},
+	} {
+		if _, err := DecodePackedVarints(bytes.NewReader(bad)); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("DecodePackedVarints(%v): got err = %v, expected = %v", bad, err, io.ErrUnexpectedEOF)
+		}
+	}
+}
+
+func TestDecodePackedVarintsLimit(t *testing.T) {
+	field := []byte{100}
+	for i := byte(0); i < 100; i++ {
+		field = append(field, i)
+	}
+	values, err := DecodePackedVarintsLimit(bytes.NewReader(field), 50)
+	if len(values) != 50 || err != ErrValueLimitExceeded {
+		t.Errorf("Got %d values, err = %v, expected 50 values and %v", len(values), err, ErrValueLimitExceeded)
+	}
+	if values, err := DecodePackedVarintsLimit(bytes.NewReader(field), 100); len(values) != 100 || err != nil {
+		t.Errorf("Got %d values, err = %v, expected 100 values", len(values), err)
+	}
+}
@@ -0,0 +1,96 @@
+package govarint
+
+import "fmt"
+import "io"
+
+// Pairs are interleaved into one group varint stream, key then value, so every group holds two pairs
+
+type U32PairEncoder struct {
+	enc *U32GroupVarintEncoder
+}
+
+func NewU32PairEncoder(w io.Writer) *U32PairEncoder {
+	return &U32PairEncoder{enc: NewU32GroupVarintEncoder(w)}
+}
+
+func (b *U32PairEncoder) PutPair(k, v uint32) error {
+	if _, err := b.enc.PutU32(k); err != nil {
+		return err
+	}
+	_, err := b.enc.PutU32(v)
+	return err
+}
+
+// Close writes any partial final group, which holds the last pair when there's an odd number of them
+func (b *U32PairEncoder) Close() error {
+	_, err := b.enc.finish()
+	return err
+}
+
+///
+
+type U32PairDecoder struct {
+	dec *U32GroupVarintDecoder
+}
+
+func NewU32PairDecoder(r io.ByteReader) *U32PairDecoder {
+	return &U32PairDecoder{dec: NewU32GroupVarintDecoder(r)}
+}
+
+// GetPair returns the next pair, or io.EOF once there are none. A key without its value is truncation.
+func (b *U32PairDecoder) GetPair() (k, v uint32, err error) {
+	k, err = b.dec.GetU32()
+	if err != nil {
+		return 0, 0, err
+	}
+	v, err = b.dec.GetU32()
+	if err == io.EOF {
+		err = errUnexpectedEOF
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	return k, v, nil
+}
+
+///
+
+// ZipEncodeU32 writes two equal length columns to w interleaved as a[0], b[0], a[1], b[1] and so on,
+// the pair layout, so correlated neighbours sit in the same group
+func ZipEncodeU32(w io.Writer, a, b []uint32) error {
+	if len(a) != len(b) {
+		return fmt.Errorf("govarint: can't zip columns of %d and %d values", len(a), len(b))
+	}
+	enc := NewU32GroupVarintEncoder(w)
+	for i := range a {
+		if _, err := enc.PutU32(a[i]); err != nil {
+			return err
+		}
+		if _, err := enc.PutU32(b[i]); err != nil {
+			return err
+		}
+	}
+	_, err := enc.finish()
+	return err
+}
+
+// UnzipDecodeU32 reads n pairs written by ZipEncodeU32 back into their two columns.
+// It fails with ErrTruncated if r holds fewer than n pairs.
+func UnzipDecodeU32(r io.ByteReader, n int) (a, b []uint32, err error) {
+	if n < 0 {
+		return nil, nil, fmt.Errorf("govarint: can't unzip %d pairs", n)
+	}
+	dec := NewU32PairDecoder(r)
+	a = make([]uint32, n)
+	b = make([]uint32, n)
+	for i := 0; i < n; i++ {
+		a[i], b[i], err = dec.GetPair()
+		if err == io.EOF {
+			err = fmt.Errorf("govarint: only %d of %d pairs: %w", i, n, ErrTruncated)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return a, b, nil
+}
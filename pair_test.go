@@ -0,0 +1,83 @@
+package govarint
+
+import "bytes"
+import "errors"
+import "io"
+import "testing"
+
+func TestU32PairRoundTrip(t *testing.T) {
+	for _, pairs := range [][][2]uint32{
+		{{1, 100}, {5, 200}},
+		{{1, 100}, {5, 200}, {1 << 20, 1<<32 - 1}},
+	} {
+		var buf bytes.Buffer
+		enc := NewU32PairEncoder(&buf)
+		for _, p := range pairs {
+			if err := enc.PutPair(p[0], p[1]); err != nil {
+				t.Fatalf("PutPair: %s", err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("Close: %s", err)
+		}
+		dec := NewU32PairDecoder(&buf)
+		for i, p := range pairs {
+			k, v, err := dec.GetPair()
+			if k != p[0] || v != p[1] || err != nil {
+				t.Errorf("Got (%d, %d), err = %v, expected (%d, %d) at %d", k, v, err, p[0], p[1], i)
+			}
+		}
+		if _, _, err := dec.GetPair(); err != io.EOF {
+			t.Errorf("Expected EOF after %d pairs, got %v", len(pairs), err)
+		}
+	}
+	// An odd number of values leaves a key with no value
+	dec := NewU32PairDecoder(bytes.NewReader(encodeU32GroupVarint([]uint32{1, 100, 5})))
+	dec.GetPair()
+	if _, _, err := dec.GetPair(); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Key without a value: got err = %v, expected ErrTruncated", err)
+	}
+}
+
+func TestU32PairEncoderCloseError(t *testing.T) {
+	// Three pairs leave the last one in a partial group, which only Close writes
+	w := &shortWriter{limit: len(encodeU32GroupVarint([]uint32{1, 100, 5, 200}))}
+	enc := NewU32PairEncoder(w)
+	for _, p := range [][2]uint32{{1, 100}, {5, 200}, {7, 300}} {
+		if err := enc.PutPair(p[0], p[1]); err != nil {
+			t.Fatalf("PutPair: %s", err)
+		}
+	}
+	if err := enc.Close(); err != io.ErrShortWrite {
+		t.Errorf("Close: got err = %v, expected io.ErrShortWrite", err)
+	}
+}
+
+func TestZipEncodeU32(t *testing.T) {
+	a := make([]uint32, 101)
+	b := make([]uint32, 101)
+	for i := range a {
+		a[i] = uint32(i * 1000)
+		b[i] = a[i] + uint32(i%3)
+	}
+	var buf bytes.Buffer
+	if err := ZipEncodeU32(&buf, a, b); err != nil {
+		t.Fatalf("ZipEncodeU32: %s", err)
+	}
+	data := buf.Bytes()
+	gotA, gotB, err := UnzipDecodeU32(bytes.NewReader(data), len(a))
+	if err != nil {
+		t.Fatalf("UnzipDecodeU32: %s", err)
+	}
+	for i := range a {
+		if gotA[i] != a[i] || gotB[i] != b[i] {
+			t.Errorf("Got pair (%d, %d), expected (%d, %d) at %d", gotA[i], gotB[i], a[i], b[i], i)
+		}
+	}
+	if _, _, err := UnzipDecodeU32(bytes.NewReader(data), len(a)+1); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Unzipping more pairs than written: expected ErrTruncated, got %v", err)
+	}
+	if err := ZipEncodeU32(&buf, a, b[1:]); err == nil {
+		t.Errorf("Columns of different lengths should be rejected")
+	}
+}
@@ -0,0 +1,124 @@
+package govarint
+
+import "encoding/binary"
+import "fmt"
+import "io"
+
+// A presence array records which positions in [0, n) are set. It's written as a mode byte
+// and n as Base128, followed by one of
+//
+//	presenceBitmap: a bitmap of n bits, least significant bit first
+//	presenceDelta:  the number of set positions, then the gaps between them, all Base128
+//
+// whichever is smaller, so dense arrays cost a bit per position and sparse ones little more
+// than a byte per set position.
+const (
+	presenceBitmap byte = 1
+	presenceDelta  byte = 2
+	// maxPresenceDeltaLength bounds a sparse array, which the decoder has to allocate in full from a length
+	// that isn't backed by input bytes. Longer arrays are always written as a bitmap.
+	maxPresenceDeltaLength = 1 << 24
+)
+
+// EncodeU32Presence writes present to w in whichever mode takes fewer bytes, returning the bytes written
+func EncodeU32Presence(w io.Writer, present []bool) (int, error) {
+	header := binary.AppendUvarint(nil, uint64(len(present)))
+	count := 0
+	for _, set := range present {
+		if set {
+			count += 1
+		}
+	}
+	sparse := binary.AppendUvarint(nil, uint64(count))
+	prev := -1
+	for i, set := range present {
+		if set {
+			// Gaps are measured from -1, so position 0 is written as 1 and a gap is never zero
+			sparse = binary.AppendUvarint(sparse, uint64(i-prev))
+			prev = i
+		}
+	}
+	out := []byte{presenceDelta}
+	if bitmapLen := (len(present) + 7) / 8; bitmapLen <= len(sparse) || len(present) > maxPresenceDeltaLength {
+		out[0] = presenceBitmap
+		sparse = make([]byte, bitmapLen)
+		for i, set := range present {
+			if set {
+				sparse[i/8] |= 1 << uint(i%8)
+			}
+		}
+	}
+	out = append(append(out, header...), sparse...)
+	return w.Write(out)
+}
+
+// DecodeU32Presence reads a presence array written by EncodeU32Presence. A bitmap is only as long as the
+// input holding it, and a sparse array longer than the encoder ever writes is rejected, so a short
+// input can't make it allocate an arbitrarily large array.
+func DecodeU32Presence(r io.ByteReader) ([]bool, error) {
+	mode, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	length, err := readPresenceUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case presenceBitmap:
+		// Grow the array as the bitmap arrives rather than trusting the length up front
+		var present []bool
+		for uint64(len(present)) < length {
+			y, err := r.ReadByte()
+			if err != nil {
+				if err == io.EOF {
+					err = errUnexpectedEOF
+				}
+				return nil, err
+			}
+			for bit := 0; bit < 8 && uint64(len(present)) < length; bit++ {
+				present = append(present, y&(1<<uint(bit)) != 0)
+			}
+		}
+		return present, nil
+	case presenceDelta:
+		count, err := readPresenceUvarint(r)
+		if err != nil {
+			return nil, err
+		}
+		if length > maxPresenceDeltaLength {
+			return nil, fmt.Errorf("govarint: sparse presence array of %d positions is too long", length)
+		}
+		if count > length {
+			return nil, fmt.Errorf("govarint: %d set positions out of %d", count, length)
+		}
+		var set []uint64
+		pos := uint64(0)
+		for i := uint64(0); i < count; i++ {
+			gap, err := readPresenceUvarint(r)
+			if err != nil {
+				return nil, err
+			}
+			if gap == 0 || gap > length-pos {
+				return nil, fmt.Errorf("govarint: presence position out of range [0, %d)", length)
+			}
+			pos += gap
+			set = append(set, pos-1)
+		}
+		present := make([]bool, length)
+		for _, i := range set {
+			present[i] = true
+		}
+		return present, nil
+	}
+	return nil, fmt.Errorf("govarint: unknown presence mode %d", mode)
+}
+
+// readPresenceUvarint reads a value from partway through a presence array, where the input ending is truncation
+func readPresenceUvarint(r io.ByteReader) (uint64, error) {
+	x, err := readUvarint(r)
+	if err == io.EOF {
+		err = errUnexpectedEOF
+	}
+	return x, err
+}
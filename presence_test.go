@@ -0,0 +1,86 @@
+package govarint
+
+import "bytes"
+import "math/rand"
+import "testing"
+
+func TestU32Presence(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	tests := []struct {
+		name    string
+		density float64
+		mode    byte
+	}{
+		{"Dense", 0.9, presenceBitmap},
+		{"Sparse", 0.01, presenceDelta},
+	}
+	for _, test := range tests {
+		present := make([]bool, 10000)
+		for i := range present {
+			present[i] = r.Float64() < test.density
+		}
+		// The first and last positions exercise the ends of the range
+		present[0] = true
+		present[len(present)-1] = true
+		var buf bytes.Buffer
+		n, err := EncodeU32Presence(&buf, present)
+		if err != nil || n != buf.Len() {
+			t.Fatalf("%s: EncodeU32Presence: got n = %d, err = %v, with %d bytes written", test.name, n, err, buf.Len())
+		}
+		if mode := buf.Bytes()[0]; mode != test.mode {
+			t.Errorf("%s: chose mode %d, expected %d", test.name, mode, test.mode)
+		}
+		if test.mode == presenceDelta && buf.Len() >= len(present)/8 {
+			t.Errorf("%s: took %d bytes, no smaller than the %d byte bitmap", test.name, buf.Len(), len(present)/8)
+		}
+		decoded, err := DecodeU32Presence(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("%s: DecodeU32Presence: %s", test.name, err)
+		}
+		if len(decoded) != len(present) {
+			t.Fatalf("%s: decoded %d positions, expected %d", test.name, len(decoded), len(present))
+		}
+		for i := range present {
+			if decoded[i] != present[i] {
+				t.Errorf("%s: got %t, expected %t at %d", test.name, decoded[i], present[i], i)
+			}
+		}
+	}
+}
+
+func TestU32PresenceCorrupt(t *testing.T) {
+	for _, bad := range [][]byte{
+		{presenceBitmap, 20, 0xff},
+		{presenceDelta, 10, 2, 1},
+		{presenceDelta, 10, 2, 5, 6},
+		{presenceDelta, 10, 11},
+		{presenceDelta, 10, 1, 0},
+		{9, 0},
+		// A huge length with nothing set, which mustn't be allocated
+		{presenceDelta, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f, 0},
+	} {
+		if _, err := DecodeU32Presence(bytes.NewReader(bad)); err == nil {
+			t.Errorf("DecodeU32Presence(%v) should fail", bad)
+		}
+	}
+	if decoded, err := DecodeU32Presence(bytes.NewReader([]byte{presenceDelta, 0, 0})); err != nil || len(decoded) != 0 {
+		t.Errorf("Empty array: got %v, err = %v", decoded, err)
+	}
+}
+
+func TestU32PresenceLongSparse(t *testing.T) {
+	// Too long for the sparse mode however few positions are set, so it's written as a bitmap and still decodes
+	present := make([]bool, maxPresenceDeltaLength+1)
+	present[len(present)-1] = true
+	var buf bytes.Buffer
+	if _, err := EncodeU32Presence(&buf, present); err != nil {
+		t.Fatalf("EncodeU32Presence: %s", err)
+	}
+	if mode := buf.Bytes()[0]; mode != presenceBitmap {
+		t.Errorf("Got mode %d, expected the bitmap", mode)
+	}
+	decoded, err := DecodeU32Presence(&buf)
+	if err != nil || len(decoded) != len(present) || !decoded[len(present)-1] || decoded[0] {
+		t.Errorf("Decoded %d positions, err = %v", len(decoded), err)
+	}
+}
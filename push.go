@@ -0,0 +1,80 @@
+package govarint
+
+import "fmt"
+
+// U32GroupVarintPushDecoder decodes group varint that's pushed into it with Write, for event driven I/O
+// where bytes arrive in whatever chunks the network delivers. Values become available from Values
+// once their whole group has arrived. A partial final group can't be recognised until the stream ends,
+// so it's only decoded by Close.
+type U32GroupVarintPushDecoder struct {
+	buf    []byte
+	values []uint32
+	closed bool
+}
+
+func NewU32GroupVarintPushDecoder() *U32GroupVarintPushDecoder {
+	return &U32GroupVarintPushDecoder{}
+}
+
+// Write buffers p and decodes every group it completes. It always consumes all of p.
+func (b *U32GroupVarintPushDecoder) Write(p []byte) (n int, err error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	b.buf = append(b.buf, p...)
+	off := 0
+	for off < len(b.buf) {
+		sizeByte := b.buf[off]
+		end := off + groupVarintGroupLen(sizeByte)
+		if end > len(b.buf) {
+			break
+		}
+		off += 1
+		for _, length := range &controlTable[sizeByte] {
+			b.values = append(b.values, groupVarintValue(b.buf[off:off+int(length)]))
+			off += int(length)
+		}
+	}
+	// Keep only the incomplete group, moving it to the front so the buffer doesn't grow without bound
+	b.buf = b.buf[:copy(b.buf, b.buf[off:])]
+	return len(p), nil
+}
+
+// Values returns the values decoded so far and not yet returned
+func (b *U32GroupVarintPushDecoder) Values() []uint32 {
+	values := b.values
+	b.values = nil
+	return values
+}
+
+// Close marks the end of the stream, decoding any bytes left over as a partial final group for Values.
+// It fails with ErrTruncated if they stop partway through a value.
+func (b *U32GroupVarintPushDecoder) Close() error {
+	b.closed = true
+	if len(b.buf) == 0 {
+		return nil
+	}
+	sizeByte := b.buf[0]
+	off := 1
+	for _, length := range &controlTable[sizeByte] {
+		if off == len(b.buf) {
+			break
+		}
+		if off+int(length) > len(b.buf) {
+			return fmt.Errorf("govarint: stream ends partway through a value: %w", ErrTruncated)
+		}
+		b.values = append(b.values, groupVarintValue(b.buf[off:off+int(length)]))
+		off += int(length)
+	}
+	b.buf = nil
+	return nil
+}
+
+// groupVarintValue decodes the big-endian bytes of a single value
+func groupVarintValue(p []byte) uint32 {
+	x := uint32(0)
+	for _, y := range p {
+		x = x<<8 | uint32(y)
+	}
+	return x
+}
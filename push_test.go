@@ -0,0 +1,41 @@
+package govarint
+
+import "errors"
+import "testing"
+
+func TestU32GroupVarintPushDecoder(t *testing.T) {
+	data := encodeU32GroupVarint(testU32)
+	dec := NewU32GroupVarintPushDecoder()
+	var decoded []uint32
+	for i := range data {
+		if n, err := dec.Write(data[i : i+1]); n != 1 || err != nil {
+			t.Fatalf("Write at %d: got n = %d, err = %v", i, n, err)
+		}
+		decoded = append(decoded, dec.Values()...)
+	}
+	// Only the partial final group is still held back
+	if len(decoded) != len(testU32)/4*4 {
+		t.Errorf("Decoded %d values before Close, expected %d", len(decoded), len(testU32)/4*4)
+	}
+	if err := dec.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	decoded = append(decoded, dec.Values()...)
+	if len(decoded) != len(testU32) {
+		t.Fatalf("%d integers were decoded when %d were encoded", len(decoded), len(testU32))
+	}
+	for i, expected := range testU32 {
+		if decoded[i] != expected {
+			t.Errorf("Got x = %d, expected = %d at %d", decoded[i], expected, i)
+		}
+	}
+	if values := dec.Values(); len(values) != 0 {
+		t.Errorf("Values were drained but got %v", values)
+	}
+	// A stream ending partway through a two byte value
+	dec = NewU32GroupVarintPushDecoder()
+	dec.Write([]byte{0x40, 1})
+	if err := dec.Close(); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated, got %v", err)
+	}
+}
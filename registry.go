@@ -0,0 +1,108 @@
+package govarint
+
+import "fmt"
+import "io"
+import "sync"
+
+type codec struct {
+	newEnc func(io.Writer) U32VarintEncoder
+	newDec func(io.ByteReader) U32VarintDecoder
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[FormatKind]codec{
+		FormatBase128: {
+			func(w io.Writer) U32VarintEncoder { return NewU32Base128Encoder(w) },
+			func(r io.ByteReader) U32VarintDecoder { return NewU32Base128Decoder(r) },
+		},
+		FormatGroupVarint: {
+			func(w io.Writer) U32VarintEncoder { return NewU32GroupVarintEncoder(w) },
+			func(r io.ByteReader) U32VarintDecoder { return NewU32GroupVarintDecoder(r) },
+		},
+		FormatDeltaGroupVarint: {
+			func(w io.Writer) U32VarintEncoder { return NewU32DeltaEncoder(NewU32GroupVarintEncoder(w)) },
+			func(r io.ByteReader) U32VarintDecoder { return NewU32DeltaDecoder(NewU32GroupVarintDecoder(r)) },
+		},
+	}
+)
+
+// RegisterCodec makes a custom encoding available under kind to EncoderForKind, DecoderForKind and
+// NewAutoU32Decoder. The built in formats are registered already, apart from FormatFORGroupVarint, whose
+// base has to come from the header. Like sql.Register, it panics if kind is taken or either function is nil,
+// as both are programming errors best caught at init time. It's safe to call from multiple goroutines.
+func RegisterCodec(kind FormatKind, newEnc func(io.Writer) U32VarintEncoder, newDec func(io.ByteReader) U32VarintDecoder) {
+	if newEnc == nil || newDec == nil {
+		panic("govarint: RegisterCodec with a nil constructor")
+	}
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	if _, taken := codecs[kind]; taken || kind == FormatFORGroupVarint {
+		panic(fmt.Sprintf("govarint: format %d is already registered", kind))
+	}
+	codecs[kind] = codec{newEnc, newDec}
+}
+
+func lookupCodec(kind FormatKind) (codec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	c, ok := codecs[kind]
+	if !ok {
+		return codec{}, fmt.Errorf("govarint: no codec registered for format %d", kind)
+	}
+	return c, nil
+}
+
+// EncoderForKind returns an encoder for the format registered under kind, writing to w
+func EncoderForKind(kind FormatKind, w io.Writer) (U32VarintEncoder, error) {
+	c, err := lookupCodec(kind)
+	if err != nil {
+		return nil, err
+	}
+	return c.newEnc(w), nil
+}
+
+// DecoderForKind returns a decoder for the format registered under kind, reading from r
+func DecoderForKind(kind FormatKind, r io.ByteReader) (U32VarintDecoder, error) {
+	c, err := lookupCodec(kind)
+	if err != nil {
+		return nil, err
+	}
+	return c.newDec(r), nil
+}
+
+// Recode decodes src as format from and writes the values to dst as format to, for migrating stored data
+// between encodings. Both kinds must be registered, which is checked before anything is read.
+// An error writing the final partial group of a built in format is returned like any other; a custom
+// encoder's Close can't report one, so its output is only as reliable as that encoder makes it.
+func Recode(dst io.Writer, src io.ByteReader, from, to FormatKind) error {
+	dec, err := DecoderForKind(from, src)
+	if err != nil {
+		return err
+	}
+	enc, err := EncoderForKind(to, dst)
+	if err != nil {
+		return err
+	}
+	_, err = CopyU32(enc, dec)
+	if err != nil {
+		enc.Close()
+		return err
+	}
+	return closeEncoder(enc)
+}
+
+// finisher is implemented by the encoders that can report an error from writing their final partial group
+type finisher interface {
+	finish() (int, error)
+}
+
+// closeEncoder closes enc, returning the error from its final write where enc can report it
+func closeEncoder(enc U32VarintEncoder) error {
+	if f, ok := enc.(finisher); ok {
+		_, err := f.finish()
+		return err
+	}
+	enc.Close()
+	return nil
+}
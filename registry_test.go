@@ -0,0 +1,131 @@
+package govarint
+
+import "bytes"
+import "io"
+import "testing"
+
+// fixedU32Encoder writes every value as four big endian bytes, as a stand in for a custom codec
+type fixedU32Encoder struct{ w io.Writer }
+
+func (e fixedU32Encoder) PutU32(x uint32) (int, error) {
+	return e.w.Write([]byte{byte(x >> 24), byte(x >> 16), byte(x >> 8), byte(x)})
+}
+
+func (e fixedU32Encoder) Close() {}
+
+type fixedU32Decoder struct{ r io.ByteReader }
+
+func (d fixedU32Decoder) GetU32() (uint32, error) {
+	x := uint32(0)
+	for i := 0; i < 4; i++ {
+		y, err := d.r.ReadByte()
+		if err != nil {
+			if i > 0 && err == io.EOF {
+				err = errUnexpectedEOF
+			}
+			return 0, err
+		}
+		x = x<<8 | uint32(y)
+	}
+	return x, nil
+}
+
+const formatFixedTest FormatKind = 200
+
+func init() {
+	RegisterCodec(formatFixedTest,
+		func(w io.Writer) U32VarintEncoder { return fixedU32Encoder{w} },
+		func(r io.ByteReader) U32VarintDecoder { return fixedU32Decoder{r} })
+}
+
+func TestCodecRegistry(t *testing.T) {
+	for _, kind := range []FormatKind{FormatBase128, FormatGroupVarint, FormatDeltaGroupVarint, formatFixedTest} {
+		var buf bytes.Buffer
+		enc, err := EncoderForKind(kind, &buf)
+		if err != nil {
+			t.Fatalf("EncoderForKind(%v): %s", kind, err)
+		}
+		for _, x := range testU32 {
+			enc.PutU32(x)
+		}
+		enc.Close()
+		if kind == formatFixedTest && buf.Len() != 4*len(testU32) {
+			t.Errorf("The registered encoder wrote %d bytes, expected %d", buf.Len(), 4*len(testU32))
+		}
+		dec, err := DecoderForKind(kind, bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("DecoderForKind(%v): %s", kind, err)
+		}
+		// A header naming the kind reaches the same decoder through the auto machinery
+		auto, err := NewAutoU32Decoder(bytes.NewReader(append([]byte{byte(kind)}, buf.Bytes()...)))
+		if err != nil {
+			t.Fatalf("NewAutoU32Decoder(%v): %s", kind, err)
+		}
+		for i, expected := range testU32 {
+			if x, err := dec.GetU32(); x != expected || err != nil {
+				t.Errorf("%v: got x = %d, err = %v, expected = %d at %d", kind, x, err, expected, i)
+			}
+			if x, err := auto.GetU32(); x != expected || err != nil {
+				t.Errorf("%v through auto: got x = %d, err = %v, expected = %d at %d", kind, x, err, expected, i)
+			}
+		}
+	}
+	if _, err := DecoderForKind(201, bytes.NewReader(nil)); err == nil {
+		t.Errorf("DecoderForKind of an unregistered kind should fail")
+	}
+	if _, err := EncoderForKind(FormatFORGroupVarint, io.Discard); err == nil {
+		t.Errorf("EncoderForKind(FORGroupVarint) should fail as it needs a base")
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Registering a kind twice should panic")
+			}
+		}()
+		RegisterCodec(FormatGroupVarint,
+			func(w io.Writer) U32VarintEncoder { return fixedU32Encoder{w} },
+			func(r io.ByteReader) U32VarintDecoder { return fixedU32Decoder{r} })
+	}()
+}
+
+func TestRecode(t *testing.T) {
+	group := encodeU32GroupVarint(testU32)
+	var base128, back bytes.Buffer
+	if err := Recode(&base128, bytes.NewReader(group), FormatGroupVarint, FormatBase128); err != nil {
+		t.Fatalf("Recode to Base128: %s", err)
+	}
+	dec := NewU32Base128Decoder(bytes.NewReader(base128.Bytes()))
+	for i, expected := range testU32 {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Errorf("Got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+		}
+	}
+	if err := Recode(&back, &base128, FormatBase128, FormatGroupVarint); err != nil {
+		t.Fatalf("Recode to group varint: %s", err)
+	}
+	if !bytes.Equal(back.Bytes(), group) {
+		t.Errorf("Recoding twice gave %x, expected %x", back.Bytes(), group)
+	}
+	var out bytes.Buffer
+	if err := Recode(&out, bytes.NewReader(group), FormatGroupVarint, 199); err == nil || out.Len() != 0 {
+		t.Errorf("Recode to an unregistered format: got err = %v after writing %d bytes", err, out.Len())
+	}
+	if err := Recode(&out, bytes.NewReader(group), 199, FormatGroupVarint); err == nil {
+		t.Errorf("Recode from an unregistered format should fail")
+	}
+}
+
+func TestRecodeFinalGroupError(t *testing.T) {
+	// Five values make a full group and a partial one, and the writer is a byte short of room for both
+	data := encodeU32GroupVarint(fiveU32)
+	for _, kind := range []FormatKind{FormatGroupVarint, FormatDeltaGroupVarint} {
+		var plain bytes.Buffer
+		if err := Recode(&plain, bytes.NewReader(data), FormatGroupVarint, kind); err != nil {
+			t.Fatalf("Recode to format %d: %s", kind, err)
+		}
+		w := &shortWriter{limit: plain.Len() - 1}
+		if err := Recode(w, bytes.NewReader(data), FormatGroupVarint, kind); err != io.ErrShortWrite {
+			t.Errorf("Recode to format %d with a failing final write: got err = %v, expected io.ErrShortWrite", kind, err)
+		}
+	}
+}
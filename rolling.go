@@ -0,0 +1,130 @@
+package govarint
+
+import "bufio"
+import "encoding/binary"
+import "fmt"
+import "io"
+import "os"
+import "path/filepath"
+
+// RollingU32Encoder writes group varint to a numbered series of files in a directory,
+// moving to the next file once the current one reaches a size limit. Files only end on a
+// group boundary and each one finishes with its value count as a little endian uint64,
+// so every file can be read with DecodeRollingU32File on its own.
+type RollingU32Encoder struct {
+	dir      string
+	maxBytes int64
+	files    []string
+	f        *os.File
+	w        *bufio.Writer
+	enc      *U32GroupVarintEncoder
+	size     int64
+	count    uint64
+	closed   bool
+}
+
+// NewRollingU32Encoder writes files to dir, which must already exist. Nothing is created until the first value.
+func NewRollingU32Encoder(dir string, maxBytesPerFile int64) *RollingU32Encoder {
+	return &RollingU32Encoder{dir: dir, maxBytes: maxBytesPerFile}
+}
+
+func (b *RollingU32Encoder) open() error {
+	path := filepath.Join(b.dir, fmt.Sprintf("%08d.gv", len(b.files)))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	b.files = append(b.files, path)
+	b.f = f
+	b.w = bufio.NewWriter(f)
+	b.enc = NewU32GroupVarintEncoder(b.w)
+	b.size = 0
+	b.count = 0
+	return nil
+}
+
+// finish writes out any partial group and the footer, then closes the current file
+func (b *RollingU32Encoder) finish() error {
+	defer func() { b.f = nil }()
+	n, err := b.enc.finish()
+	b.size += int64(n)
+	if err == nil {
+		var footer [8]byte
+		binary.LittleEndian.PutUint64(footer[:], b.count)
+		_, err = b.w.Write(footer[:])
+	}
+	if err == nil {
+		err = b.w.Flush()
+	}
+	if closeErr := b.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (b *RollingU32Encoder) PutU32(x uint32) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	if b.f == nil {
+		if err := b.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := b.enc.PutU32(x)
+	b.count += 1
+	b.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	// Only a completed group (n > 0) leaves the file on a group boundary
+	if n > 0 && b.size >= b.maxBytes {
+		return n, b.finish()
+	}
+	return n, nil
+}
+
+// Files returns the paths written so far, in order
+func (b *RollingU32Encoder) Files() []string {
+	return b.files
+}
+
+// Close finishes the current file
+func (b *RollingU32Encoder) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	if b.f == nil {
+		return nil
+	}
+	return b.finish()
+}
+
+// DecodeRollingU32File decodes the contents of one file written by a RollingU32Encoder,
+// checking the values against the file's footer count. Empty data decodes to nil with no error.
+func DecodeRollingU32File(data []byte) ([]uint32, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if len(data) < 8 {
+		return nil, fmt.Errorf("govarint: rolling file too short for its footer: %w", ErrTruncated)
+	}
+	count := binary.LittleEndian.Uint64(data[len(data)-8:])
+	dec := NewU32GroupVarintSliceDecoder(data[:len(data)-8])
+	var xs []uint32
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, x)
+	}
+	if uint64(len(xs)) != count {
+		return nil, fmt.Errorf("govarint: rolling file holds %d values but its footer says %d: %w", len(xs), count, ErrCountMismatch)
+	}
+	return xs, nil
+}
@@ -0,0 +1,49 @@
+package govarint
+
+import "os"
+import "testing"
+
+func TestRollingU32Encoder(t *testing.T) {
+	// 302 single byte values make 75 groups of five bytes and a partial group of two, and a file
+	// is finished after the group that takes it to 128 bytes, giving 26 + 26 + 23 groups plus the partial one
+	data := make([]uint32, 302)
+	for i := range data {
+		data[i] = uint32(i % 256)
+	}
+	enc := NewRollingU32Encoder(t.TempDir(), 128)
+	for _, x := range data {
+		if _, err := enc.PutU32(x); err != nil {
+			t.Fatalf("PutU32: %s", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	files := enc.Files()
+	if len(files) != 3 {
+		t.Fatalf("Wrote %d files, expected 3", len(files))
+	}
+	var decoded []uint32
+	for i, path := range files {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Reading %s: %s", path, err)
+		}
+		xs, err := DecodeRollingU32File(contents)
+		if err != nil {
+			t.Fatalf("DecodeRollingU32File(%s): %s", path, err)
+		}
+		if expected := []int{104, 104, 94}[i]; len(xs) != expected {
+			t.Errorf("File %d holds %d values, expected %d", i, len(xs), expected)
+		}
+		decoded = append(decoded, xs...)
+	}
+	if len(decoded) != len(data) {
+		t.Fatalf("%d integers were decoded when %d were encoded", len(decoded), len(data))
+	}
+	for i, expected := range data {
+		if decoded[i] != expected {
+			t.Errorf("Got x = %d, expected = %d at %d", decoded[i], expected, i)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package govarint
+
+import "fmt"
+import "io"
+
+// SegmentInfo describes one segment written by EncodeU32Segments: where it starts in the output,
+// how many bytes it takes and how many values it holds
+type SegmentInfo struct {
+	Offset  int64
+	ByteLen int
+	Count   int
+}
+
+// EncodeU32Segments writes xs to w as consecutive, independent group varint segments of at most
+// maxSegmentBytes each, for page oriented layouts. Each segment starts its own groups, so any one can
+// be decoded from its descriptor alone, and every segment, not just the last, may end in a partial group.
+// Segments are filled greedily in order. It fails if maxSegmentBytes is too small to hold some value.
+func EncodeU32Segments(w io.Writer, xs []uint32, maxSegmentBytes int) ([]SegmentInfo, error) {
+	var segments []SegmentInfo
+	var offset int64
+	for start := 0; start < len(xs); {
+		// Take values while they fit, counting a size byte for every fourth
+		count, length := 0, 0
+		for start+count < len(xs) {
+			cost := groupVarintLen(xs[start+count])
+			if count%4 == 0 {
+				cost += 1
+			}
+			if length+cost > maxSegmentBytes {
+				break
+			}
+			count += 1
+			length += cost
+		}
+		if count == 0 {
+			return segments, fmt.Errorf("govarint: value %d doesn't fit in a %d byte segment", start, maxSegmentBytes)
+		}
+		enc := NewU32GroupVarintEncoder(w)
+		written := 0
+		for _, x := range xs[start : start+count] {
+			n, err := enc.PutU32(x)
+			written += n
+			if err != nil {
+				return segments, err
+			}
+		}
+		n, err := enc.finish()
+		written += n
+		if err != nil {
+			return segments, err
+		}
+		segments = append(segments, SegmentInfo{Offset: offset, ByteLen: written, Count: count})
+		offset += int64(written)
+		start += count
+	}
+	return segments, nil
+}
@@ -0,0 +1,43 @@
+package govarint
+
+import "bytes"
+import "io"
+import "math/rand"
+import "testing"
+
+func TestEncodeU32Segments(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	xs := make([]uint32, 10000)
+	for i := range xs {
+		xs[i] = uint32(r.Int63n(1 << uint(r.Intn(32)+1)))
+	}
+	var buf bytes.Buffer
+	segments, err := EncodeU32Segments(&buf, xs, 100)
+	if err != nil {
+		t.Fatalf("EncodeU32Segments: %s", err)
+	}
+	data := buf.Bytes()
+	next, i := int64(0), 0
+	for _, seg := range segments {
+		if seg.Offset != next || seg.ByteLen > 100 || seg.Count == 0 {
+			t.Fatalf("Segment %+v doesn't follow on from offset %d within 100 bytes", seg, next)
+		}
+		next += int64(seg.ByteLen)
+		dec := NewU32GroupVarintSliceDecoder(data[seg.Offset:next])
+		for j := 0; j < seg.Count; j++ {
+			if x, err := dec.GetU32(); x != xs[i] || err != nil {
+				t.Errorf("Got x = %d, err = %v, expected = %d at %d", x, err, xs[i], i)
+			}
+			i += 1
+		}
+		if _, err := dec.GetU32(); err != io.EOF {
+			t.Errorf("Expected EOF after the %d values of segment %+v, got %v", seg.Count, seg, err)
+		}
+	}
+	if i != len(xs) || next != int64(len(data)) {
+		t.Errorf("Segments cover %d values in %d bytes, expected %d in %d", i, next, len(xs), len(data))
+	}
+	if _, err := EncodeU32Segments(io.Discard, []uint32{1 << 31}, 4); err == nil {
+		t.Errorf("A four byte value shouldn't fit in a four byte segment")
+	}
+}
@@ -0,0 +1,206 @@
+package govarint
+
+import "fmt"
+import "io"
+import "sort"
+
+// EncodeU32Set writes the members of set to w in increasing order as delta encoded group varint,
+// the layout NewU32BitmapDecoder reads, and returns the number of bytes written
+func EncodeU32Set(w io.Writer, set map[uint32]struct{}) (int, error) {
+	keys := make([]uint32, 0, len(set))
+	for x := range set {
+		keys = append(keys, x)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	group := NewU32GroupVarintEncoder(w)
+	enc := NewU32DeltaEncoder(group)
+	written := 0
+	for _, x := range keys {
+		n, err := enc.PutU32(x)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	n, err := group.finish()
+	return written + n, err
+}
+
+// DecodeU32Set reads a set written by EncodeU32Set. Empty input is the empty set, with no error;
+// the map is still allocated so callers can add to it.
+func DecodeU32Set(r io.ByteReader) (map[uint32]struct{}, error) {
+	set := make(map[uint32]struct{})
+	dec := NewU32DeltaDecoder(NewU32GroupVarintDecoder(r))
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			return set, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		set[x] = struct{}{}
+	}
+}
+
+// CardinalityU32 counts the members of a set written by EncodeU32Set, or any delta encoded group varint stream,
+// without materializing them. Deltas don't change the count, so this only decodes the groups.
+func CardinalityU32(r io.ByteReader) (uint64, error) {
+	dec := NewU32GroupVarintDecoder(r)
+	var count uint64
+	for {
+		_, n, err := dec.GetGroup()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		count += uint64(n)
+	}
+}
+
+// AppendSortedU32 merges the sorted values of additions into existing, a sorted delta encoded group varint
+// stream such as EncodeU32Set writes, and writes the merged stream to dst in the same format.
+// Values are written once however many times they occur across the two inputs, so merging into a
+// strictly increasing stream keeps it strictly increasing. additions must be in non-decreasing order,
+// otherwise ErrNotMonotonic is returned.
+func AppendSortedU32(dst io.Writer, existing io.ByteReader, additions []uint32) error {
+	for i := 1; i < len(additions); i++ {
+		if additions[i] < additions[i-1] {
+			return fmt.Errorf("govarint: additions out of order at %d: %w", i, ErrNotMonotonic)
+		}
+	}
+	group := NewU32GroupVarintEncoder(dst)
+	enc := NewU32DeltaEncoder(group)
+	dec := NewU32DeltaDecoder(NewU32GroupVarintDecoder(existing))
+	started := false
+	var last uint32
+	put := func(x uint32) error {
+		if started && x == last {
+			return nil
+		}
+		started = true
+		last = x
+		_, err := enc.PutU32(x)
+		return err
+	}
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		for len(additions) > 0 && additions[0] < x {
+			if err := put(additions[0]); err != nil {
+				return err
+			}
+			additions = additions[1:]
+		}
+		if err := put(x); err != nil {
+			return err
+		}
+	}
+	for _, x := range additions {
+		if err := put(x); err != nil {
+			return err
+		}
+	}
+	_, err := group.finish()
+	return err
+}
+
+// EncodeSortedU32 writes xs to w in increasing order as delta encoded group varint, sorting a copy so
+// the caller's slice is left as it was, and returns the number of bytes written. Duplicates are kept,
+// as deltas of zero, for DecodeSortedU32 to drop or not.
+func EncodeSortedU32(w io.Writer, xs []uint32) (int, error) {
+	sorted := append([]uint32(nil), xs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	group := NewU32GroupVarintEncoder(w)
+	enc := NewU32DeltaEncoder(group)
+	written := 0
+	for _, x := range sorted {
+		n, err := enc.PutU32(x)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	n, err := group.finish()
+	return written + n, err
+}
+
+// DecodeSortedU32 reads the values written by EncodeSortedU32, in increasing order, dropping repeats if dedup
+// is set. Empty input gives a nil slice and no error.
+func DecodeSortedU32(r io.ByteReader, dedup bool) ([]uint32, error) {
+	dec := NewU32DeltaDecoder(NewU32GroupVarintDecoder(r))
+	dec.Dedup = dedup
+	var xs []uint32
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			return xs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, x)
+	}
+}
+
+// WindowDedupU32Encoder writes near-sorted values as group varint, buffering window values at a time and
+// emitting each window sorted with its duplicates removed. The dedup is window-local, not global: a value
+// seen again in a later window is written again, so it only suits streams whose repeats arrive close together.
+type WindowDedupU32Encoder struct {
+	enc    *U32GroupVarintEncoder
+	window []uint32
+	size   int
+}
+
+func NewWindowDedupU32Encoder(w io.Writer, window int) *WindowDedupU32Encoder {
+	if window < 1 {
+		window = 1
+	}
+	return &WindowDedupU32Encoder{enc: NewU32GroupVarintEncoder(w), window: make([]uint32, 0, window), size: window}
+}
+
+// PutU32 adds x to the current window, returning the bytes written if that filled and emitted the window
+func (b *WindowDedupU32Encoder) PutU32(x uint32) (int, error) {
+	b.window = append(b.window, x)
+	if len(b.window) < b.size {
+		return 0, nil
+	}
+	return b.emit()
+}
+
+func (b *WindowDedupU32Encoder) emit() (int, error) {
+	xs := b.window
+	sort.Slice(xs, func(i, j int) bool { return xs[i] < xs[j] })
+	written := 0
+	for i, x := range xs {
+		if i > 0 && x == xs[i-1] {
+			continue
+		}
+		n, err := b.enc.PutU32(x)
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	b.window = b.window[:0]
+	return written, nil
+}
+
+// Close emits the last, possibly short, window and any partial group
+func (b *WindowDedupU32Encoder) Close() error {
+	if b.enc.closed {
+		return nil
+	}
+	if _, err := b.emit(); err != nil {
+		return err
+	}
+	_, err := b.enc.finish()
+	return err
+}
@@ -0,0 +1,186 @@
+package govarint
+
+import "bytes"
+import "errors"
+import "testing"
+
+func TestEncodeU32Set(t *testing.T) {
+	set := make(map[uint32]struct{})
+	for _, x := range randomU32s(1200) {
+		if len(set) == 1000 {
+			break
+		}
+		set[x] = struct{}{}
+	}
+	if len(set) != 1000 {
+		t.Fatalf("Only built a set of %d keys", len(set))
+	}
+	var buf bytes.Buffer
+	n, err := EncodeU32Set(&buf, set)
+	if err != nil || n != buf.Len() {
+		t.Fatalf("EncodeU32Set: got n = %d, err = %v, with %d bytes written", n, err, buf.Len())
+	}
+	decoded, err := DecodeU32Set(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("DecodeU32Set: %s", err)
+	}
+	if len(decoded) != len(set) {
+		t.Fatalf("Decoded a set of %d keys, expected %d", len(decoded), len(set))
+	}
+	for x := range set {
+		if _, ok := decoded[x]; !ok {
+			t.Errorf("Decoded set is missing %d", x)
+		}
+	}
+	// The encoding is the sorted delta layout the bitmap decoder reads
+	bitmap, err := NewU32BitmapDecoder(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewU32BitmapDecoder: %s", err)
+	}
+	if bitmap.Len() != len(set) {
+		t.Errorf("NewU32BitmapDecoder: got %d members, expected %d", bitmap.Len(), len(set))
+	}
+	buf.Reset()
+	if n, err := EncodeU32Set(&buf, nil); n != 0 || err != nil || buf.Len() != 0 {
+		t.Errorf("Empty set: got n = %d, err = %v, %d bytes", n, err, buf.Len())
+	}
+	if decoded, err := DecodeU32Set(bytes.NewReader(nil)); err != nil || len(decoded) != 0 {
+		t.Errorf("Empty input: got %d keys, err = %v", len(decoded), err)
+	}
+}
+
+func TestCardinalityU32(t *testing.T) {
+	set := make(map[uint32]struct{})
+	for i := uint32(0); len(set) < 1000; i++ {
+		set[i*i*7919] = struct{}{}
+	}
+	var buf bytes.Buffer
+	if _, err := EncodeU32Set(&buf, set); err != nil {
+		t.Fatalf("EncodeU32Set: %s", err)
+	}
+	if count, err := CardinalityU32(&buf); count != 1000 || err != nil {
+		t.Errorf("CardinalityU32: got %d, err = %v, expected 1000", count, err)
+	}
+	if count, err := CardinalityU32(bytes.NewReader(nil)); count != 0 || err != nil {
+		t.Errorf("CardinalityU32 of nothing: got %d, err = %v", count, err)
+	}
+}
+
+func TestAppendSortedU32(t *testing.T) {
+	tests := []struct {
+		existing, additions, expected []uint32
+	}{
+		{[]uint32{1, 3, 5}, []uint32{2, 4}, []uint32{1, 2, 3, 4, 5}},
+		{[]uint32{1, 3, 5}, []uint32{0, 6, 7}, []uint32{0, 1, 3, 5, 6, 7}},
+		{[]uint32{1, 3, 5}, []uint32{3, 3, 4}, []uint32{1, 3, 4, 5}},
+		{nil, []uint32{2, 4}, []uint32{2, 4}},
+		{[]uint32{1, 3, 5}, nil, []uint32{1, 3, 5}},
+	}
+	for _, test := range tests {
+		var out bytes.Buffer
+		if err := AppendSortedU32(&out, encodeU32DeltaSet(test.existing), test.additions); err != nil {
+			t.Fatalf("AppendSortedU32(%v, %v): %s", test.existing, test.additions, err)
+		}
+		dec := NewU32DeltaDecoder(NewU32GroupVarintDecoder(&out))
+		var merged []uint32
+		for {
+			x, err := dec.GetU32()
+			if err != nil {
+				break
+			}
+			merged = append(merged, x)
+		}
+		if len(merged) != len(test.expected) {
+			t.Fatalf("Merging %v into %v: got %v, expected %v", test.additions, test.existing, merged, test.expected)
+		}
+		for i := range test.expected {
+			if merged[i] != test.expected[i] {
+				t.Errorf("Merging %v into %v: got %v, expected %v", test.additions, test.existing, merged, test.expected)
+				break
+			}
+		}
+	}
+	var out bytes.Buffer
+	if err := AppendSortedU32(&out, encodeU32DeltaSet([]uint32{1}), []uint32{4, 2}); !errors.Is(err, ErrNotMonotonic) {
+		t.Errorf("Unsorted additions: got err = %v, expected ErrNotMonotonic", err)
+	}
+}
+
+func TestEncodeSortedU32(t *testing.T) {
+	xs := []uint32{40, 7, 1 << 31, 7, 0, 300}
+	original := append([]uint32(nil), xs...)
+	var buf bytes.Buffer
+	n, err := EncodeSortedU32(&buf, xs)
+	if n != buf.Len() || err != nil {
+		t.Fatalf("EncodeSortedU32: got n = %d, err = %v, expected n = %d", n, err, buf.Len())
+	}
+	for i := range xs {
+		if xs[i] != original[i] {
+			t.Fatalf("EncodeSortedU32 changed its input to %v", xs)
+		}
+	}
+	for _, test := range []struct {
+		dedup    bool
+		expected []uint32
+	}{
+		{false, []uint32{0, 7, 7, 40, 300, 1 << 31}},
+		{true, []uint32{0, 7, 40, 300, 1 << 31}},
+	} {
+		decoded, err := DecodeSortedU32(bytes.NewReader(buf.Bytes()), test.dedup)
+		if err != nil {
+			t.Fatalf("DecodeSortedU32: %s", err)
+		}
+		if len(decoded) != len(test.expected) {
+			t.Fatalf("DecodeSortedU32 with dedup %v: got %v, expected %v", test.dedup, decoded, test.expected)
+		}
+		for i, expected := range test.expected {
+			if decoded[i] != expected {
+				t.Errorf("Got x = %d, expected = %d at %d", decoded[i], expected, i)
+			}
+		}
+	}
+	if decoded, err := DecodeSortedU32(bytes.NewReader(nil), false); decoded != nil || err != nil {
+		t.Errorf("DecodeSortedU32 of nothing: got %v, err = %v", decoded, err)
+	}
+}
+
+func TestWindowDedupU32Encoder(t *testing.T) {
+	tests := []struct {
+		in, expected []uint32
+	}{
+		// The first window is [1 1 2 1] and the short last one is [3]
+		{[]uint32{1, 1, 2, 1, 3}, []uint32{1, 2, 3}},
+		// Duplicates in different windows are both kept, and each window is sorted on its own
+		{[]uint32{4, 3, 2, 1, 1, 0}, []uint32{1, 2, 3, 4, 0, 1}},
+		{nil, nil},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		enc := NewWindowDedupU32Encoder(&buf, 4)
+		for _, x := range test.in {
+			if _, err := enc.PutU32(x); err != nil {
+				t.Fatalf("PutU32: %s", err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("Close: %s", err)
+		}
+		dec := NewU32GroupVarintSliceDecoder(buf.Bytes())
+		var decoded []uint32
+		for {
+			x, err := dec.GetU32()
+			if err != nil {
+				break
+			}
+			decoded = append(decoded, x)
+		}
+		if len(decoded) != len(test.expected) {
+			t.Fatalf("Encoding %v decoded to %v, expected %v", test.in, decoded, test.expected)
+		}
+		for i, expected := range test.expected {
+			if decoded[i] != expected {
+				t.Errorf("Got x = %d, expected = %d at %d", decoded[i], expected, i)
+			}
+		}
+	}
+}
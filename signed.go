@@ -0,0 +1,132 @@
+package govarint
+
+import "io"
+
+// Signed integers are mapped onto unsigned ones using zigzag encoding
+// 0 => 0, -1 => 1, 1 => 2, -2 => 3, ... so that small magnitudes stay small
+func zigzagEncode32(x int32) uint32 {
+	return uint32(x<<1) ^ uint32(x>>31)
+}
+
+func zigzagDecode32(x uint32) int32 {
+	return int32(x>>1) ^ -int32(x&1)
+}
+
+func zigzagEncode64(x int64) uint64 {
+	return uint64(x<<1) ^ uint64(x>>63)
+}
+
+func zigzagDecode64(x uint64) int64 {
+	return int64(x>>1) ^ -int64(x&1)
+}
+
+///
+
+type I32GroupVarintEncoder struct {
+	enc *U32GroupVarintEncoder
+}
+
+func NewI32GroupVarintEncoder(w io.Writer) *I32GroupVarintEncoder {
+	return &I32GroupVarintEncoder{enc: NewU32GroupVarintEncoder(w)}
+}
+
+func (b *I32GroupVarintEncoder) PutI32(x int32) (int, error) {
+	return b.enc.PutU32(zigzagEncode32(x))
+}
+
+// PutI32s writes every value of xs, returning the bytes written by the groups they completed
+func (b *I32GroupVarintEncoder) PutI32s(xs []int32) (int, error) {
+	written := 0
+	for _, x := range xs {
+		n, err := b.enc.PutU32(zigzagEncode32(x))
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (b *I32GroupVarintEncoder) Close() {
+	b.enc.Close()
+}
+
+///
+
+type I32GroupVarintDecoder struct {
+	dec *U32GroupVarintDecoder
+}
+
+func NewI32GroupVarintDecoder(r io.ByteReader) *I32GroupVarintDecoder {
+	return &I32GroupVarintDecoder{dec: NewU32GroupVarintDecoder(r)}
+}
+
+// GetI32 returns the next value. Group varint holds at most 32 bits per value and zigzag maps every
+// uint32 onto an int32, so unlike Base128's GetU32 there's no value that can overflow; errors are
+// the underlying decoder's, with a zero value as for the unsigned decoders.
+func (b *I32GroupVarintDecoder) GetI32() (int32, error) {
+	x, err := b.dec.GetU32()
+	if err != nil {
+		return 0, err
+	}
+	return zigzagDecode32(x), nil
+}
+
+// GetI32s fills dst with the next len(dst) values and returns how many were read.
+// If the stream ends first, the short count is returned along with io.EOF.
+func (b *I32GroupVarintDecoder) GetI32s(dst []int32) (int, error) {
+	d := b.dec
+	n := 0
+	for n < len(dst) {
+		if d.pos == d.capacity {
+			if d.finished {
+				return n, io.EOF
+			}
+			err := d.getGroup()
+			if err != nil {
+				return n, err
+			}
+			// A partial group may hold no values at all, so check again before reading
+			continue
+		}
+		// Rather than going through GetI32 for every value, un-zigzag the rest of the group at once
+		group := d.group[d.pos:d.capacity]
+		if len(group) > len(dst)-n {
+			group = group[:len(dst)-n]
+		}
+		for i, x := range group {
+			dst[n+i] = zigzagDecode32(x)
+		}
+		n += len(group)
+		d.pos += len(group)
+	}
+	return n, nil
+}
+
+// GetI64s is GetI32s for engines that keep signed columns as int64, widening each value as it's
+// un-zigzagged rather than going through an []int32. Every value fits, so there's nothing to overflow.
+func (b *I32GroupVarintDecoder) GetI64s(dst []int64) (int, error) {
+	d := b.dec
+	n := 0
+	for n < len(dst) {
+		if d.pos == d.capacity {
+			if d.finished {
+				return n, io.EOF
+			}
+			if err := d.getGroup(); err != nil {
+				return n, err
+			}
+			continue
+		}
+		group := d.group[d.pos:d.capacity]
+		if len(group) > len(dst)-n {
+			group = group[:len(dst)-n]
+		}
+		for i, x := range group {
+			dst[n+i] = int64(zigzagDecode32(x))
+		}
+		n += len(group)
+		d.pos += len(group)
+	}
+	return n, nil
+}
@@ -0,0 +1,169 @@
+package govarint
+
+import "bytes"
+import "io"
+import "math"
+import "testing"
+
+var testI32 = []int32{
+	0,
+	-1,
+	1,
+	-64,
+	63,
+	math.MinInt32,
+	7,
+	-1000000,
+	42,
+	math.MaxInt32,
+	-2,
+}
+
+func TestI32ZigzagMinInt32(t *testing.T) {
+	// The most negative value maps onto the largest unsigned value, which needs all four bytes
+	if x := zigzagEncode32(math.MinInt32); x != math.MaxUint32 {
+		t.Errorf("zigzagEncode32(MinInt32): got %d, expected %d", x, uint32(math.MaxUint32))
+	}
+	var buf bytes.Buffer
+	enc := NewI32GroupVarintEncoder(&buf)
+	enc.PutI32(math.MinInt32)
+	enc.Close()
+	// One size byte plus a single four byte value
+	if buf.Len() != 5 {
+		t.Errorf("Encoding MinInt32 took %d bytes, expected 5", buf.Len())
+	}
+	dec := NewI32GroupVarintDecoder(&buf)
+	dst := make([]int32, 4)
+	n, err := dec.GetI32s(dst)
+	if n != 1 || err != io.EOF || dst[0] != math.MinInt32 {
+		t.Errorf("GetI32s: got n = %d, x = %d, err = %v, expected n = 1, x = %d, err = EOF", n, dst[0], err, int32(math.MinInt32))
+	}
+}
+
+func TestI32GroupVarintGetI32s(t *testing.T) {
+	for _, window := range []int{1, 3, 4, 5, len(testI32)} {
+		var buf bytes.Buffer
+		enc := NewI32GroupVarintEncoder(&buf)
+		for _, x := range testI32 {
+			enc.PutI32(x)
+		}
+		enc.Close()
+		dec := NewI32GroupVarintDecoder(&buf)
+		var decoded []int32
+		dst := make([]int32, window)
+		for {
+			n, err := dec.GetI32s(dst)
+			decoded = append(decoded, dst[:n]...)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("GetI32s with window %d: %s", window, err)
+			}
+		}
+		if len(decoded) != len(testI32) {
+			t.Fatalf("Window %d: %d integers were decoded when %d were encoded", window, len(decoded), len(testI32))
+		}
+		for i, expected := range testI32 {
+			if decoded[i] != expected {
+				t.Errorf("Window %d: got x = %d, expected = %d at %d", window, decoded[i], expected, i)
+			}
+		}
+	}
+}
+
+func TestI32GroupVarintGetI64s(t *testing.T) {
+	data := append(append([]int32{}, testI32...), -1, math.MinInt32, math.MaxInt32, -300)
+	for _, window := range []int{1, 3, 4, 5, len(data)} {
+		var buf bytes.Buffer
+		enc := NewI32GroupVarintEncoder(&buf)
+		enc.PutI32s(data)
+		enc.Close()
+		dec := NewI32GroupVarintDecoder(&buf)
+		var decoded []int64
+		dst := make([]int64, window)
+		for {
+			n, err := dec.GetI64s(dst)
+			decoded = append(decoded, dst[:n]...)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("GetI64s with window %d: %s", window, err)
+			}
+		}
+		if len(decoded) != len(data) {
+			t.Fatalf("Window %d: %d integers were decoded when %d were encoded", window, len(decoded), len(data))
+		}
+		for i, expected := range data {
+			if decoded[i] != int64(expected) {
+				t.Errorf("Window %d: got x = %d, expected = %d at %d", window, decoded[i], expected, i)
+			}
+		}
+	}
+}
+
+func TestI32GroupVarintBoundaries(t *testing.T) {
+	// The values either side of each change in encoded length, which zigzag puts at ±2^(8k-1)
+	values := []int32{math.MinInt32, math.MinInt32 + 1, math.MaxInt32, math.MaxInt32 - 1}
+	for _, k := range []uint{7, 15, 23} {
+		edge := int32(1) << k
+		values = append(values, edge-1, edge, -edge, -edge-1)
+	}
+	lengths := []int{4, 4, 4, 4, 1, 2, 1, 2, 2, 3, 2, 3, 3, 4, 3, 4}
+	for i, x := range values {
+		if length := groupVarintLen(zigzagEncode32(x)); length != lengths[i] {
+			t.Errorf("%d takes %d bytes, expected %d", x, length, lengths[i])
+		}
+		if y := zigzagDecode32(zigzagEncode32(x)); y != x {
+			t.Errorf("Zigzag round trip of %d gave %d", x, y)
+		}
+	}
+	var buf bytes.Buffer
+	enc := NewI32GroupVarintEncoder(&buf)
+	if _, err := enc.PutI32s(values[:5]); err != nil {
+		t.Fatalf("PutI32s: %s", err)
+	}
+	for _, x := range values[5:] {
+		if _, err := enc.PutI32(x); err != nil {
+			t.Fatalf("PutI32: %s", err)
+		}
+	}
+	enc.Close()
+	if _, err := enc.PutI32s([]int32{1}); err != ErrClosed {
+		t.Errorf("PutI32s after Close: got err = %v, expected ErrClosed", err)
+	}
+	dec := NewI32GroupVarintDecoder(&buf)
+	for i, expected := range values {
+		if x, err := dec.GetI32(); x != expected || err != nil {
+			t.Errorf("GetI32(): got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+		}
+	}
+	if x, err := dec.GetI32(); x != 0 || err != io.EOF {
+		t.Errorf("GetI32() at the end: got x = %d, err = %v, expected 0 and EOF", x, err)
+	}
+}
+
+func TestI32GroupVarintPutI32s(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewI32GroupVarintEncoder(&buf)
+	// The first two groups complete within the call, the rest is left for Close
+	n, err := enc.PutI32s(testI32)
+	if err != nil {
+		t.Fatalf("PutI32s: %s", err)
+	}
+	if n != buf.Len() {
+		t.Errorf("PutI32s reported %d bytes, %d were written", n, buf.Len())
+	}
+	enc.Close()
+	dst := make([]int32, len(testI32)+1)
+	got, err := NewI32GroupVarintDecoder(&buf).GetI32s(dst)
+	if got != len(testI32) || err != io.EOF {
+		t.Fatalf("GetI32s: got n = %d, err = %v, expected n = %d and EOF", got, err, len(testI32))
+	}
+	for i, expected := range testI32 {
+		if dst[i] != expected {
+			t.Errorf("Got x = %d, expected = %d at %d", dst[i], expected, i)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package govarint
+
+import "io"
+
+// SQLiteVarintDecoder reads the varints of SQLite's file format.
+// These differ from base128 in two ways: the bytes are big endian, most significant group first,
+// and a value runs to at most nine bytes, with the ninth contributing all eight of its bits
+// rather than seven plus a continuation bit. There's no overlong case, as nine bytes hold 64 bits exactly.
+type SQLiteVarintDecoder struct {
+	r io.ByteReader
+}
+
+func NewSQLiteVarintDecoder(r io.ByteReader) *SQLiteVarintDecoder {
+	return &SQLiteVarintDecoder{r: r}
+}
+
+func (b *SQLiteVarintDecoder) GetU64() (uint64, error) {
+	var x uint64
+	for i := 0; i < 9; i++ {
+		y, err := b.r.ReadByte()
+		if err != nil {
+			if i > 0 && err == io.EOF {
+				err = errUnexpectedEOF
+			}
+			return 0, err
+		}
+		if i == 8 {
+			return x<<8 | uint64(y), nil
+		}
+		x = x<<7 | uint64(y&0x7f)
+		if y < 0x80 {
+			return x, nil
+		}
+	}
+	// Unreachable, the ninth byte always ends the value
+	return x, nil
+}
@@ -0,0 +1,45 @@
+package govarint
+
+import "bytes"
+import "errors"
+import "io"
+import "testing"
+
+func TestSQLiteVarintDecoder(t *testing.T) {
+	cases := []struct {
+		data     []byte
+		expected uint64
+	}{
+		{[]byte{0x00}, 0},
+		{[]byte{0x7f}, 127},
+		{[]byte{0x81, 0x00}, 128},
+		{[]byte{0x82, 0x2c}, 300},
+		{[]byte{0xff, 0x7f}, 16383},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}, 1<<56 - 1},
+		{[]byte{0x81, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}, 1 << 57},
+		{[]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0xff}, 0xff},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, 1<<64 - 1},
+	}
+	for _, c := range cases {
+		dec := NewSQLiteVarintDecoder(bytes.NewReader(c.data))
+		x, err := dec.GetU64()
+		if x != c.expected || err != nil {
+			t.Errorf("Decoding %x: got x = %d, err = %v, expected = %d", c.data, x, err, c.expected)
+		}
+		if _, err := dec.GetU64(); err != io.EOF {
+			t.Errorf("Decoding %x: expected io.EOF after the value, got %v", c.data, err)
+		}
+	}
+	// Nine continuation bytes are a complete value, not an overlong one, so the stream carries on
+	dec := NewSQLiteVarintDecoder(bytes.NewReader([]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x05}))
+	if x, err := dec.GetU64(); x != 0x80 || err != nil {
+		t.Errorf("Got x = %d, err = %v, expected = 128", x, err)
+	}
+	if x, err := dec.GetU64(); x != 5 || err != nil {
+		t.Errorf("Got x = %d, err = %v, expected = 5", x, err)
+	}
+	dec = NewSQLiteVarintDecoder(bytes.NewReader([]byte{0x81, 0x80}))
+	if _, err := dec.GetU64(); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated decoding a cut off value, got %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+package govarint
+
+import "bytes"
+import "fmt"
+import "io"
+import "math"
+import "math/bits"
+import "math/rand"
+import "time"
+
+// GroupVarintEncodedLen returns the exact number of bytes the group varint encoder writes for xs
+func GroupVarintEncodedLen(xs []uint32) int {
+	length := (len(xs) + 3) / 4
+	for _, x := range xs {
+		length += groupVarintLen(x)
+	}
+	return length
+}
+
+// SampleEncodedSize estimates GroupVarintEncodedLen(xs) by measuring a sampleRate fraction of xs,
+// drawn at random indices, and scaling up. Only the sampled values are visited, so it costs a fraction of
+// the exact count. The sample is drawn from a fixed seed, so the same input always gives the same estimate.
+// A sampleRate outside (0, 1) measures the whole slice.
+func SampleEncodedSize(xs []uint32, sampleRate float64) int64 {
+	if sampleRate <= 0 || sampleRate >= 1 {
+		return int64(GroupVarintEncodedLen(xs))
+	}
+	// The size bytes only depend on the count, so they don't need estimating
+	controlLen := int64(len(xs)+3) / 4
+	sampled := int(sampleRate*float64(len(xs)) + 0.5)
+	if sampled == 0 {
+		if len(xs) == 0 {
+			return 0
+		}
+		sampled = 1
+	}
+	r := rand.New(rand.NewSource(1))
+	sampledLen := 0
+	for i := 0; i < sampled; i++ {
+		sampledLen += groupVarintLen(xs[r.Intn(len(xs))])
+	}
+	return controlLen + int64(float64(sampledLen)/float64(sampled)*float64(len(xs))+0.5)
+}
+
+// BitWidthHistogram counts the values of xs needing each bit width, where entry w is the number of values
+// whose highest set bit is bit w-1 and entry 0 counts the zeros. It gives a bit-packing encoder the
+// numbers it needs to pick a width, or to decide varint would be smaller.
+func BitWidthHistogram(xs []uint32) [33]int {
+	var counts [33]int
+	for _, x := range xs {
+		counts[bits.Len32(x)] += 1
+	}
+	return counts
+}
+
+// CompressibilityU32 returns the size of xs as group varint relative to four bytes a value. Near 1 the values
+// are wide and varint won't help; the floor, for values that all fit in a byte, is 0.3125, as every group
+// also needs its size byte. An empty slice gives 1.
+func CompressibilityU32(xs []uint32) float64 {
+	if len(xs) == 0 {
+		return 1
+	}
+	return float64(GroupVarintEncodedLen(xs)) / float64(4*len(xs))
+}
+
+// EncodedByteLen consumes the group varint stream r, reading only the size bytes, and returns its length in bytes,
+// for framing data whose length wasn't stored. Value bytes are skipped with Discard if r has it, as a
+// *bufio.Reader does, or by seeking if it's an io.Seeker, and are only read one at a time otherwise.
+// It fails with ErrTruncated if the stream stops partway through a value.
+func EncodedByteLen(r io.ByteReader) (int64, error) {
+	var length int64
+	for {
+		sizeByte, err := r.ReadByte()
+		if err == io.EOF {
+			return length, nil
+		}
+		if err != nil {
+			return length, err
+		}
+		length += 1
+		want := int(controlTableTotal[sizeByte])
+		skipped, err := skipBytes(r, want)
+		length += int64(skipped)
+		if skipped == want {
+			continue
+		}
+		if err != io.EOF {
+			return length, err
+		}
+		// Only a partial final group ending between values can stop short
+		end := 0
+		for _, size := range &controlTable[sizeByte] {
+			end += int(size)
+			if end >= skipped {
+				break
+			}
+		}
+		if skipped == 0 || end != skipped {
+			return length, fmt.Errorf("govarint: stream ends partway through a value at byte %d: %w", length, ErrTruncated)
+		}
+		return length, nil
+	}
+}
+
+// skipBytes passes over up to n bytes of r, returning how many it skipped and io.EOF if that was fewer
+func skipBytes(r io.ByteReader, n int) (int, error) {
+	switch r := r.(type) {
+	case interface{ Discard(int) (int, error) }:
+		return r.Discard(n)
+	case io.Seeker:
+		cur, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, err
+		}
+		end, err := r.Seek(0, io.SeekEnd)
+		if err != nil {
+			return 0, err
+		}
+		skip := int64(n)
+		if end-cur < skip {
+			skip = end - cur
+		}
+		if _, err := r.Seek(cur+skip, io.SeekStart); err != nil {
+			return 0, err
+		}
+		if skip < int64(n) {
+			return int(skip), io.EOF
+		}
+		return n, nil
+	}
+	for i := 0; i < n; i++ {
+		if _, err := r.ReadByte(); err != nil {
+			return i, err
+		}
+	}
+	return n, nil
+}
+
+// DecodeNanosPerByte is the decode speed EstimateDecodeCost assumes, in nanoseconds per byte of group varint.
+// The default is a rough figure for the slice decoder on current hardware; set it, or call CalibrateDecodeCost,
+// for one that fits the machine. Either must happen before any concurrent use of EstimateDecodeCost.
+var DecodeNanosPerByte = 5.0
+
+// EstimateDecodeCost returns roughly how long decoding byteLen bytes of group varint takes, for a scheduler
+// choosing between decoding eagerly and lazily. It's linear in byteLen and only as good as DecodeNanosPerByte.
+func EstimateDecodeCost(byteLen int64) time.Duration {
+	return time.Duration(float64(byteLen) * DecodeNanosPerByte)
+}
+
+// calibrationValues is how many values CalibrateDecodeCost decodes per pass
+const calibrationValues = 1 << 16
+
+// CalibrateDecodeCost times the slice decoder on this machine and sets DecodeNanosPerByte from the fastest
+// of a few passes, returning the new value. It takes a few milliseconds, so it's left to callers to run once
+// at startup if they want it rather than happening at init.
+func CalibrateDecodeCost() float64 {
+	r := rand.New(rand.NewSource(1))
+	xs := make([]uint32, calibrationValues)
+	for i := range xs {
+		// Spread the values over every byte length
+		xs[i] = r.Uint32() >> (8 * uint(r.Intn(4)))
+	}
+	var buf bytes.Buffer
+	enc := NewU32GroupVarintEncoderSized(&buf, len(xs))
+	for _, x := range xs {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	data := buf.Bytes()
+	best := time.Duration(math.MaxInt64)
+	for pass := 0; pass < 5; pass++ {
+		start := time.Now()
+		dec := NewU32GroupVarintSliceDecoder(data)
+		for {
+			if _, err := dec.GetU32(); err != nil {
+				break
+			}
+		}
+		if elapsed := time.Since(start); elapsed < best {
+			best = elapsed
+		}
+	}
+	DecodeNanosPerByte = float64(best.Nanoseconds()) / float64(len(data))
+	return DecodeNanosPerByte
+}
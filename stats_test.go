@@ -0,0 +1,122 @@
+package govarint
+
+import "bufio"
+import "bytes"
+import "errors"
+import "io"
+import "math/rand"
+import "testing"
+import "time"
+
+func TestGroupVarintEncodedLen(t *testing.T) {
+	for n := 0; n <= len(fiveU32); n++ {
+		if length := GroupVarintEncodedLen(fiveU32[:n]); length != len(encodeU32GroupVarint(fiveU32[:n])) {
+			t.Errorf("GroupVarintEncodedLen of %d values: got %d, expected %d", n, length, len(encodeU32GroupVarint(fiveU32[:n])))
+		}
+	}
+}
+
+func TestSampleEncodedSize(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	xs := make([]uint32, 100000)
+	for i := range xs {
+		xs[i] = uint32(r.Int63n(1 << 24))
+	}
+	exact := int64(GroupVarintEncodedLen(xs))
+	estimate := SampleEncodedSize(xs, 0.05)
+	// Within 2% of the real size
+	if diff := estimate - exact; diff*50 > exact || -diff*50 > exact {
+		t.Errorf("SampleEncodedSize: got %d, exact size is %d", estimate, exact)
+	}
+	if estimate := SampleEncodedSize(xs, 1); estimate != exact {
+		t.Errorf("SampleEncodedSize at rate 1: got %d, expected %d", estimate, exact)
+	}
+}
+
+func TestBitWidthHistogram(t *testing.T) {
+	xs := []uint32{0, 0, 1, 2, 3, 4, 7, 255, 256, 1<<31 - 1, 1 << 31, 1<<32 - 1}
+	var expected [33]int
+	expected[0], expected[1], expected[2], expected[3] = 2, 1, 2, 2
+	expected[8], expected[9], expected[31], expected[32] = 1, 1, 1, 2
+	if counts := BitWidthHistogram(xs); counts != expected {
+		t.Errorf("BitWidthHistogram: got %v, expected %v", counts, expected)
+	}
+	if counts := BitWidthHistogram(nil); counts != [33]int{} {
+		t.Errorf("BitWidthHistogram of nothing: got %v", counts)
+	}
+}
+
+func TestCompressibilityU32(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	small := make([]uint32, 1000)
+	full := make([]uint32, 1000)
+	for i := range small {
+		small[i] = uint32(r.Intn(256))
+		full[i] = r.Uint32()
+	}
+	if c := CompressibilityU32(small); c != 0.3125 {
+		t.Errorf("CompressibilityU32 of single byte values: got %f, expected 0.3125", c)
+	}
+	// Random values almost all take four bytes, and the size bytes push the ratio over 1
+	if c := CompressibilityU32(full); c < 1 || c > 1.0625 {
+		t.Errorf("CompressibilityU32 of full range values: got %f, expected between 1 and 1.0625", c)
+	}
+	if c := CompressibilityU32(nil); c != 1 {
+		t.Errorf("CompressibilityU32 of nothing: got %f, expected 1", c)
+	}
+}
+
+func TestEncodedByteLen(t *testing.T) {
+	for n := 0; n <= len(testU32); n++ {
+		data := encodeU32GroupVarint(testU32[:n])
+		readers := []io.ByteReader{
+			bytes.NewReader(data),
+			bufio.NewReader(bytes.NewReader(data)),
+			oneByteAtATime{bytes.NewReader(data)},
+		}
+		for _, r := range readers {
+			if length, err := EncodedByteLen(r); length != int64(len(data)) || err != nil {
+				t.Errorf("EncodedByteLen of %d values with %T: got %d, err = %v, expected %d", n, r, length, err, len(data))
+			}
+		}
+	}
+	// 300 takes two bytes but only one is left
+	truncated := []byte{0x10, 1, 1}
+	readers := []io.ByteReader{
+		bytes.NewReader(truncated),
+		bufio.NewReader(bytes.NewReader(truncated)),
+		oneByteAtATime{bytes.NewReader(truncated)},
+	}
+	for _, r := range readers {
+		if _, err := EncodedByteLen(r); !errors.Is(err, ErrTruncated) {
+			t.Errorf("EncodedByteLen with %T: expected ErrTruncated, got %v", r, err)
+		}
+	}
+}
+
+func TestEstimateDecodeCost(t *testing.T) {
+	defer func(saved float64) { DecodeNanosPerByte = saved }(DecodeNanosPerByte)
+	if perByte := CalibrateDecodeCost(); perByte <= 0 || perByte != DecodeNanosPerByte {
+		t.Errorf("CalibrateDecodeCost: got %f, DecodeNanosPerByte is %f", perByte, DecodeNanosPerByte)
+	}
+	DecodeNanosPerByte = 2
+	for _, n := range []int64{0, 1, 1000, 1 << 30} {
+		if cost := EstimateDecodeCost(n); cost != time.Duration(2*n) {
+			t.Errorf("EstimateDecodeCost(%d): got %s, expected %s", n, cost, time.Duration(2*n))
+		}
+		if double := EstimateDecodeCost(2 * n); double != 2*EstimateDecodeCost(n) {
+			t.Errorf("EstimateDecodeCost(%d) = %s isn't twice EstimateDecodeCost(%d)", 2*n, double, n)
+		}
+	}
+}
+
+func TestSampleEncodedSizeSmall(t *testing.T) {
+	// Too few values for a single sample at this rate still measures one, and all of them take a byte
+	xs := []uint32{1, 2, 3, 4, 5}
+	if estimate := SampleEncodedSize(xs, 0.01); estimate != int64(GroupVarintEncodedLen(xs)) {
+		t.Errorf("SampleEncodedSize: got %d, expected %d", estimate, GroupVarintEncodedLen(xs))
+	}
+	if estimate := SampleEncodedSize(nil, 0.5); estimate != 0 {
+		t.Errorf("SampleEncodedSize of nothing: got %d", estimate)
+	}
+}
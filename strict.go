@@ -0,0 +1,101 @@
+package govarint
+
+type DecodeStatus int
+
+const (
+	DecodeOK DecodeStatus = iota
+	// The input ended partway through a value
+	DecodeTruncated
+	// A value doesn't fit in 32 bits
+	DecodeOverflow
+	// A value was encoded with more bytes than necessary
+	DecodeNonCanonical
+	// More values were present than the caller allowed
+	DecodeValueLimitExceeded
+)
+
+func (s DecodeStatus) String() string {
+	switch s {
+	case DecodeOK:
+		return "OK"
+	case DecodeTruncated:
+		return "Truncated"
+	case DecodeOverflow:
+		return "Overflow"
+	case DecodeNonCanonical:
+		return "NonCanonical"
+	case DecodeValueLimitExceeded:
+		return "ValueLimitExceeded"
+	}
+	return "Unknown"
+}
+
+type DecodeResult struct {
+	// Count is the number of values decoded successfully
+	Count int
+	// BytesConsumed is the length of the input covered by those values
+	BytesConsumed int
+	Status        DecodeStatus
+}
+
+// Err returns the package error matching Status, or nil if decoding finished cleanly
+func (r DecodeResult) Err() error {
+	switch r.Status {
+	case DecodeTruncated:
+		return errUnexpectedEOF
+	case DecodeOverflow:
+		return ErrOverflow
+	case DecodeNonCanonical:
+		return ErrNonCanonical
+	case DecodeValueLimitExceeded:
+		return ErrValueLimitExceeded
+	}
+	return nil
+}
+
+// uvarint32 strictly decodes one Base128 value from the front of data, returning it and its length
+func uvarint32(data []byte) (uint32, int, DecodeStatus) {
+	x := uint32(0)
+	for i, b := range data {
+		if i == 4 && b > 0x0f {
+			// The fifth byte only has room for the top four bits, and can't continue
+			return 0, 0, DecodeOverflow
+		}
+		x |= uint32(b&0x7f) << (7 * uint(i))
+		if b < 0x80 {
+			// A final zero byte after the first adds nothing and should have been left off
+			if b == 0 && i > 0 {
+				return 0, 0, DecodeNonCanonical
+			}
+			return x, i + 1, DecodeOK
+		}
+	}
+	return 0, 0, DecodeTruncated
+}
+
+// DecodeU32AllStrict decodes a buffer of Base128 values, rejecting anything other than canonical
+// 32 bit varints. Decoding stops at the first bad value; the values before it are returned and
+// the result says how far decoding got and why it stopped. Empty data gives nil values and DecodeOK.
+func DecodeU32AllStrict(data []byte) ([]uint32, DecodeResult) {
+	return DecodeU32AllStrictLimit(data, -1)
+}
+
+// DecodeU32AllStrictLimit is DecodeU32AllStrict for untrusted input, returning at most maxValues values.
+// If data holds more, decoding stops after maxValues with the status DecodeValueLimitExceeded.
+// A negative maxValues means no limit.
+func DecodeU32AllStrictLimit(data []byte, maxValues int) ([]uint32, DecodeResult) {
+	var values []uint32
+	off := 0
+	for off < len(data) {
+		if len(values) == maxValues {
+			return values, DecodeResult{Count: len(values), BytesConsumed: off, Status: DecodeValueLimitExceeded}
+		}
+		x, n, status := uvarint32(data[off:])
+		if status != DecodeOK {
+			return values, DecodeResult{Count: len(values), BytesConsumed: off, Status: status}
+		}
+		values = append(values, x)
+		off += n
+	}
+	return values, DecodeResult{Count: len(values), BytesConsumed: off, Status: DecodeOK}
+}
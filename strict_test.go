@@ -0,0 +1,68 @@
+package govarint
+
+import "bytes"
+import "errors"
+import "testing"
+
+func TestDecodeU32AllStrict(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewU32Base128Encoder(&buf)
+	for _, x := range fiveU32 {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	valid := buf.Bytes()
+	tests := []struct {
+		name     string
+		suffix   []byte
+		expected DecodeStatus
+	}{
+		{"OK", nil, DecodeOK},
+		{"Truncated", []byte{0x80, 0x80}, DecodeTruncated},
+		{"Overflow", []byte{0xff, 0xff, 0xff, 0xff, 0x10}, DecodeOverflow},
+		{"Overflow continuation", []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x00}, DecodeOverflow},
+		{"NonCanonical", []byte{0x81, 0x00}, DecodeNonCanonical},
+	}
+	for _, test := range tests {
+		data := append(append([]byte{}, valid...), test.suffix...)
+		values, result := DecodeU32AllStrict(data)
+		if result.Status != test.expected {
+			t.Errorf("%s: got status %v, expected %v", test.name, result.Status, test.expected)
+		}
+		// Everything before the bad value still decodes
+		if result.Count != len(fiveU32) || len(values) != len(fiveU32) || result.BytesConsumed != len(valid) {
+			t.Errorf("%s: got %d values (count %d) over %d bytes, expected %d values over %d bytes",
+				test.name, len(values), result.Count, result.BytesConsumed, len(fiveU32), len(valid))
+		}
+		for i, expected := range fiveU32[:len(values)] {
+			if values[i] != expected {
+				t.Errorf("%s: got x = %d, expected = %d at %d", test.name, values[i], expected, i)
+			}
+		}
+	}
+	// The largest value uses the whole fifth byte and is still valid
+	values, result := DecodeU32AllStrict([]byte{0xff, 0xff, 0xff, 0xff, 0x0f})
+	if result.Status != DecodeOK || len(values) != 1 || values[0] != 1<<32-1 {
+		t.Errorf("MaxUint32: got %v with status %v", values, result.Status)
+	}
+}
+
+func TestDecodeU32AllStrictLimit(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewU32Base128Encoder(&buf)
+	for i := uint32(0); i < 100; i++ {
+		enc.PutU32(i)
+	}
+	enc.Close()
+	values, result := DecodeU32AllStrictLimit(buf.Bytes(), 50)
+	if result.Status != DecodeValueLimitExceeded || !errors.Is(result.Err(), ErrValueLimitExceeded) {
+		t.Errorf("Got status %v, err = %v, expected %v", result.Status, result.Err(), DecodeValueLimitExceeded)
+	}
+	if len(values) != 50 || result.Count != 50 || result.BytesConsumed != 50 {
+		t.Errorf("Got %d values (count %d) over %d bytes, expected 50 over 50", len(values), result.Count, result.BytesConsumed)
+	}
+	// A limit the input fits within changes nothing
+	if values, result := DecodeU32AllStrictLimit(buf.Bytes(), 100); result.Status != DecodeOK || len(values) != 100 {
+		t.Errorf("Got %d values with status %v, expected 100 with %v", len(values), result.Status, DecodeOK)
+	}
+}
@@ -0,0 +1,120 @@
+package govarint
+
+import "errors"
+import "io"
+
+// Sync markers make a group varint stream seekable without an index. Any byte can turn up in group varint,
+// so the stream is escaped JPEG style: syncEscape is written as syncEscape syncLiteral, which leaves
+// syncEscape syncMarker free to mean a marker. Markers only ever sit between groups, so decoding can
+// resume straight after one.
+const (
+	syncEscape  = 0xff
+	syncLiteral = 0x00
+	syncMarker  = 0x01
+)
+
+var errBadSyncEscape = errors.New("govarint: invalid escape in sync marked stream")
+
+// syncMarkerWriter escapes everything written through it and adds a marker before every everyN'th write.
+// The group varint encoder writes each group with a single Write, so writes are groups.
+type syncMarkerWriter struct {
+	w      io.Writer
+	everyN int
+	groups int
+	buf    []byte
+}
+
+func (s *syncMarkerWriter) Write(p []byte) (int, error) {
+	s.buf = s.buf[:0]
+	if s.groups > 0 && s.groups%s.everyN == 0 {
+		s.buf = append(s.buf, syncEscape, syncMarker)
+	}
+	for _, y := range p {
+		s.buf = append(s.buf, y)
+		if y == syncEscape {
+			s.buf = append(s.buf, syncLiteral)
+		}
+	}
+	s.groups += 1
+	if _, err := s.w.Write(s.buf); err != nil {
+		// What made it out can't be mapped back onto p, unescaped
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WithSyncMarkers makes the encoder escape its output and write a sync marker after every everyN groups,
+// for reading with a U32SyncMarkerDecoder. It must be called before any values are written, and returns b.
+// Sizes returned by PutU32 and Flush are still those of the unescaped groups.
+func (b *U32GroupVarintEncoder) WithSyncMarkers(everyN int) *U32GroupVarintEncoder {
+	if everyN < 1 {
+		everyN = 1
+	}
+	b.w = &syncMarkerWriter{w: b.w, everyN: everyN}
+	return b
+}
+
+///
+
+// syncMarkerReader unescapes a sync marked stream, passing over markers
+type syncMarkerReader struct {
+	r io.ByteReader
+}
+
+func (s *syncMarkerReader) ReadByte() (byte, error) {
+	for {
+		y, err := s.r.ReadByte()
+		if err != nil || y != syncEscape {
+			return y, err
+		}
+		z, err := s.r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				err = errUnexpectedEOF
+			}
+			return 0, err
+		}
+		switch z {
+		case syncLiteral:
+			return syncEscape, nil
+		case syncMarker:
+			continue
+		}
+		return 0, errBadSyncEscape
+	}
+}
+
+// U32SyncMarkerDecoder reads group varint written by an encoder using WithSyncMarkers
+type U32SyncMarkerDecoder struct {
+	r   io.ByteReader
+	dec *U32GroupVarintDecoder
+}
+
+func NewU32SyncMarkerDecoder(r io.ByteReader) *U32SyncMarkerDecoder {
+	return &U32SyncMarkerDecoder{r: r, dec: NewU32GroupVarintDecoder(&syncMarkerReader{r: r})}
+}
+
+func (b *U32SyncMarkerDecoder) GetU32() (uint32, error) {
+	return b.dec.GetU32()
+}
+
+// SeekNextMarker drops the rest of the current group and scans forward to just after the next marker,
+// so decoding resumes with the group that follows it. It returns io.EOF if there are no more markers.
+// The scan only looks at escapes, so it also serves to resynchronize after corrupt data.
+func (b *U32SyncMarkerDecoder) SeekNextMarker() error {
+	b.dec.pos = 4
+	b.dec.capacity = 4
+	b.dec.finished = false
+	escaped := false
+	for {
+		y, err := b.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		// Corrupt data can leave an escape followed by another, which may be the start of the marker
+		if escaped && y == syncMarker {
+			return nil
+		}
+		escaped = y == syncEscape
+	}
+}
@@ -0,0 +1,65 @@
+package govarint
+
+import "bytes"
+import "io"
+import "testing"
+
+func TestU32SyncMarkers(t *testing.T) {
+	// Plenty of 0xff bytes to escape, and a partial final group
+	values := randomU32s(1002)
+	for i := 0; i < len(values); i += 5 {
+		values[i] = 0xffffffff
+	}
+	var buf bytes.Buffer
+	enc := NewU32GroupVarintEncoder(&buf).WithSyncMarkers(16)
+	for _, x := range values {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	// Reading straight through passes over every marker
+	dec := NewU32SyncMarkerDecoder(bytes.NewReader(buf.Bytes()))
+	for i, expected := range values {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Fatalf("Got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Fatalf("Expected EOF after every value, got %v", err)
+	}
+	// The second marker comes after 32 groups, even from partway through the first group
+	dec = NewU32SyncMarkerDecoder(bytes.NewReader(buf.Bytes()))
+	dec.GetU32()
+	for i := 0; i < 2; i++ {
+		if err := dec.SeekNextMarker(); err != nil {
+			t.Fatalf("SeekNextMarker: %s", err)
+		}
+	}
+	for i, expected := range values[32*4:] {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Fatalf("After the second marker: got x = %d, err = %v, expected = %d at %d", x, err, expected, 32*4+i)
+		}
+	}
+	// 251 groups have markers after 16, 32, ..., 240
+	dec = NewU32SyncMarkerDecoder(bytes.NewReader(buf.Bytes()))
+	markers := 0
+	for dec.SeekNextMarker() == nil {
+		markers += 1
+	}
+	if markers != 15 {
+		t.Errorf("Found %d markers, expected 15", markers)
+	}
+}
+
+func TestU32SyncMarkersCorruptEscape(t *testing.T) {
+	// A stray escape straight before the marker's escape mustn't hide the marker
+	stream := []byte{0x05, syncEscape, syncEscape, syncMarker, 0x00, 1, 2, 3, 4}
+	dec := NewU32SyncMarkerDecoder(bytes.NewReader(stream))
+	if err := dec.SeekNextMarker(); err != nil {
+		t.Fatalf("SeekNextMarker: %s", err)
+	}
+	for i, expected := range []uint32{1, 2, 3, 4} {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Errorf("Got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+		}
+	}
+}
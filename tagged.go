@@ -0,0 +1,116 @@
+package govarint
+
+import "encoding/binary"
+import "io"
+
+// A tagged stream holds both 32 and 64 bit values. They're written in batches of eight as a tag byte,
+// whose bit i is set if value i of the batch is 64 bit, followed by the eight values in Base128.
+// As with a partial group, a short final batch is told apart by running out of values.
+
+type TaggedEncoder struct {
+	w      io.Writer
+	values [8]uint64
+	tag    byte
+	index  int
+	buf    []byte
+	closed bool
+}
+
+func NewTaggedEncoder(w io.Writer) *TaggedEncoder {
+	return &TaggedEncoder{w: w}
+}
+
+func (b *TaggedEncoder) put(x uint64, is64 bool) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	if is64 {
+		b.tag |= 1 << uint(b.index)
+	}
+	b.values[b.index] = x
+	b.index += 1
+	if b.index < len(b.values) {
+		return 0, nil
+	}
+	return b.flush()
+}
+
+func (b *TaggedEncoder) flush() (int, error) {
+	buf := append(b.buf[:0], b.tag)
+	for _, x := range b.values[:b.index] {
+		buf = binary.AppendUvarint(buf, x)
+	}
+	b.buf = buf
+	b.tag = 0
+	b.index = 0
+	return b.w.Write(buf)
+}
+
+// PutU32 adds a value tagged as 32 bit, returning the bytes written if it completed a batch
+func (b *TaggedEncoder) PutU32(x uint32) (int, error) {
+	return b.put(uint64(x), false)
+}
+
+// PutU64 adds a value tagged as 64 bit, whatever its magnitude
+func (b *TaggedEncoder) PutU64(x uint64) (int, error) {
+	return b.put(x, true)
+}
+
+// Close writes any short final batch, returning any error from doing so
+func (b *TaggedEncoder) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	if b.index == 0 {
+		return nil
+	}
+	_, err := b.flush()
+	return err
+}
+
+///
+
+type TaggedDecoder struct {
+	r        io.ByteReader
+	tag      byte
+	index    int
+	started  bool
+	finished bool
+}
+
+func NewTaggedDecoder(r io.ByteReader) *TaggedDecoder {
+	return &TaggedDecoder{r: r}
+}
+
+// Next returns the next value and whether it was written with PutU64, or io.EOF once there are none
+func (b *TaggedDecoder) Next() (value uint64, is64 bool, err error) {
+	if b.finished {
+		return 0, false, io.EOF
+	}
+	if !b.started || b.index == 8 {
+		b.tag, err = b.r.ReadByte()
+		if err != nil {
+			b.finished = true
+			return 0, false, err
+		}
+		b.started = true
+		b.index = 0
+	}
+	value, err = readUvarint(b.r)
+	if err == io.EOF && b.index == 0 {
+		// A tag byte has to be followed by at least one value
+		err = errUnexpectedEOF
+	}
+	if err != nil {
+		b.finished = true
+		return 0, false, err
+	}
+	is64 = b.tag&(1<<uint(b.index)) != 0
+	if !is64 && value > 1<<32-1 {
+		b.finished = true
+		return 0, false, ErrOverflow
+	}
+	b.index += 1
+	return value, is64, nil
+}
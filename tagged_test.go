@@ -0,0 +1,54 @@
+package govarint
+
+import "bytes"
+import "errors"
+import "io"
+import "testing"
+
+func TestTaggedRoundTrip(t *testing.T) {
+	type tagged struct {
+		value uint64
+		is64  bool
+	}
+	var data []tagged
+	for i, x := range testU32 {
+		// A 64 bit value can be small, and the tag still has to come back
+		data = append(data, tagged{uint64(x), false}, tagged{uint64(i) << uint(i%64), true})
+	}
+	for n := 0; n <= len(data); n += 3 {
+		var buf bytes.Buffer
+		enc := NewTaggedEncoder(&buf)
+		for _, x := range data[:n] {
+			var err error
+			if x.is64 {
+				_, err = enc.PutU64(x.value)
+			} else {
+				_, err = enc.PutU32(uint32(x.value))
+			}
+			if err != nil {
+				t.Fatalf("Put: %s", err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("Close: %s", err)
+		}
+		dec := NewTaggedDecoder(&buf)
+		for i, expected := range data[:n] {
+			if x, is64, err := dec.Next(); x != expected.value || is64 != expected.is64 || err != nil {
+				t.Errorf("Got x = %d, is64 = %v, err = %v, expected = %d, is64 = %v at %d", x, is64, err, expected.value, expected.is64, i)
+			}
+		}
+		if _, _, err := dec.Next(); err != io.EOF {
+			t.Errorf("Expected EOF after %d values, got %v", n, err)
+		}
+	}
+	// A value tagged as 32 bit that doesn't fit
+	dec := NewTaggedDecoder(bytes.NewReader([]byte{0, 0x80, 0x80, 0x80, 0x80, 0x10}))
+	if _, _, err := dec.Next(); err != ErrOverflow {
+		t.Errorf("Expected ErrOverflow, got %v", err)
+	}
+	dec = NewTaggedDecoder(bytes.NewReader([]byte{0}))
+	if _, _, err := dec.Next(); !errors.Is(err, ErrTruncated) {
+		t.Errorf("Expected ErrTruncated for a tag without values, got %v", err)
+	}
+}
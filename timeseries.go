@@ -0,0 +1,74 @@
+package govarint
+
+import "io"
+import "time"
+
+// Timestamps are stored as ticks of a fixed resolution since an epoch, delta-of-delta encoded:
+// each value is the change in the gap from the previous timestamp, zigzag encoded and written as
+// Base128. Regularly spaced samples come out as a run of zero bytes, and jitter stays small.
+
+type TimeSeriesEncoder struct {
+	enc       *Base128Encoder
+	epoch     time.Time
+	res       time.Duration
+	prev      int64
+	prevDelta int64
+}
+
+// NewTimeSeriesEncoder writes times to w as ticks of resolution since epoch.
+// Times are truncated towards the epoch to a whole number of ticks, and may fall before it.
+// A resolution of zero or less is treated as a nanosecond, as it is by NewTimeSeriesDecoder.
+func NewTimeSeriesEncoder(w io.Writer, epoch time.Time, resolution time.Duration) *TimeSeriesEncoder {
+	if resolution < 1 {
+		resolution = 1
+	}
+	return &TimeSeriesEncoder{enc: NewU64Base128Encoder(w), epoch: epoch, res: resolution}
+}
+
+func (b *TimeSeriesEncoder) PutTime(t time.Time) error {
+	ticks := int64(t.Sub(b.epoch) / b.res)
+	delta := ticks - b.prev
+	_, err := b.enc.PutU64(zigzagEncode64(delta - b.prevDelta))
+	if err != nil {
+		return err
+	}
+	b.prev = ticks
+	b.prevDelta = delta
+	return nil
+}
+
+// Close stops further writes. Every time has gone out by the time PutTime returns, so there's
+// nothing left to fail and the error is always nil; it's there to match the other encoders.
+func (b *TimeSeriesEncoder) Close() error {
+	b.enc.Close()
+	return nil
+}
+
+///
+
+type TimeSeriesDecoder struct {
+	r         io.ByteReader
+	epoch     time.Time
+	res       time.Duration
+	prev      int64
+	prevDelta int64
+}
+
+// NewTimeSeriesDecoder reads times written by a TimeSeriesEncoder, which must have used the same epoch and resolution.
+// A resolution of zero or less is treated as a nanosecond, matching the encoder.
+func NewTimeSeriesDecoder(r io.ByteReader, epoch time.Time, resolution time.Duration) *TimeSeriesDecoder {
+	if resolution < 1 {
+		resolution = 1
+	}
+	return &TimeSeriesDecoder{r: r, epoch: epoch, res: resolution}
+}
+
+func (b *TimeSeriesDecoder) GetTime() (time.Time, error) {
+	x, err := readUvarint(b.r)
+	if err != nil {
+		return time.Time{}, err
+	}
+	b.prevDelta += zigzagDecode64(x)
+	b.prev += b.prevDelta
+	return b.epoch.Add(time.Duration(b.prev) * b.res), nil
+}
@@ -0,0 +1,79 @@
+package govarint
+
+import "bytes"
+import "io"
+import "testing"
+import "time"
+
+func TestTimeSeriesRoundTrip(t *testing.T) {
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := epoch.Add(90 * 24 * time.Hour)
+	// A sample every ten seconds over a day, with every seventh one running a second or two late
+	var times []time.Time
+	for i := 0; i < 8640; i++ {
+		ts := start.Add(time.Duration(i) * 10 * time.Second)
+		if i%7 == 0 {
+			ts = ts.Add(time.Duration(1+i%2) * time.Second)
+		}
+		times = append(times, ts)
+	}
+	var buf bytes.Buffer
+	enc := NewTimeSeriesEncoder(&buf, epoch, time.Second)
+	for _, ts := range times {
+		if err := enc.PutTime(ts); err != nil {
+			t.Fatalf("PutTime: %s", err)
+		}
+	}
+	enc.Close()
+	// Regular gaps cost a byte each, with the jitter costing no more
+	if buf.Len() > len(times)+8 {
+		t.Errorf("%d times took %d bytes", len(times), buf.Len())
+	}
+	dec := NewTimeSeriesDecoder(&buf, epoch, time.Second)
+	for i, expected := range times {
+		ts, err := dec.GetTime()
+		if err != nil || !ts.Equal(expected) {
+			t.Fatalf("GetTime(): got %v, err = %v, expected %v at %d", ts, err, expected, i)
+		}
+	}
+	if _, err := dec.GetTime(); err != io.EOF {
+		t.Errorf("Expected EOF after every time, got %v", err)
+	}
+}
+
+func TestTimeSeriesBeforeEpochAndTruncation(t *testing.T) {
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	enc := NewTimeSeriesEncoder(&buf, epoch, time.Second)
+	enc.PutTime(epoch.Add(-time.Hour))
+	enc.PutTime(epoch.Add(1500 * time.Millisecond))
+	enc.Close()
+	dec := NewTimeSeriesDecoder(&buf, epoch, time.Second)
+	for _, expected := range []time.Time{epoch.Add(-time.Hour), epoch.Add(time.Second)} {
+		if ts, err := dec.GetTime(); err != nil || !ts.Equal(expected) {
+			t.Errorf("GetTime(): got %v, err = %v, expected %v", ts, err, expected)
+		}
+	}
+}
+
+func TestTimeSeriesZeroResolution(t *testing.T) {
+	// A zero resolution is taken as nanosecond ticks rather than dividing by zero
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	times := []time.Time{epoch.Add(time.Nanosecond), epoch.Add(time.Second + 7)}
+	var buf bytes.Buffer
+	enc := NewTimeSeriesEncoder(&buf, epoch, 0)
+	for _, ts := range times {
+		if err := enc.PutTime(ts); err != nil {
+			t.Fatalf("PutTime: %s", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	dec := NewTimeSeriesDecoder(&buf, epoch, -time.Second)
+	for _, expected := range times {
+		if ts, err := dec.GetTime(); err != nil || !ts.Equal(expected) {
+			t.Errorf("GetTime(): got %v, err = %v, expected %v", ts, err, expected)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package govarint
+
+import "io"
+
+// Transforms stream one group varint encoding into another, holding only a group at a time in memory
+
+// MapU32 decodes every value from the group varint stream src, applies fn and writes the result to dst as group varint
+func MapU32(dst io.Writer, src io.ByteReader, fn func(uint32) uint32) error {
+	enc := NewU32GroupVarintEncoder(dst)
+	dec := NewU32GroupVarintDecoder(src)
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := enc.PutU32(fn(x)); err != nil {
+			return err
+		}
+	}
+	// Close would drop the error from writing the final group, so flush it ourselves
+	_, err := enc.Flush()
+	return err
+}
+
+// FilterU32 re-encodes only the values of src for which keep returns true, and returns how many were kept.
+// It works on the values exactly as stored, so for a delta encoded stream keep sees the deltas;
+// decode to absolute values first if that's what the predicate needs.
+func FilterU32(dst io.Writer, src io.ByteReader, keep func(uint32) bool) (kept int, err error) {
+	enc := NewU32GroupVarintEncoder(dst)
+	dec := NewU32GroupVarintDecoder(src)
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return kept, err
+		}
+		if !keep(x) {
+			continue
+		}
+		if _, err := enc.PutU32(x); err != nil {
+			return kept, err
+		}
+		kept += 1
+	}
+	_, err = enc.Flush()
+	return kept, err
+}
+
+// PartitionU32 re-encodes each value of src to matchW if pred returns true for it and to elseW if not,
+// splitting one column into two, and returns how many went each way. Like FilterU32 it sees the values
+// exactly as stored.
+func PartitionU32(matchW, elseW io.Writer, src io.ByteReader, pred func(uint32) bool) (matched, unmatched int, err error) {
+	match := NewU32GroupVarintEncoder(matchW)
+	other := NewU32GroupVarintEncoder(elseW)
+	dec := NewU32GroupVarintDecoder(src)
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return matched, unmatched, err
+		}
+		if pred(x) {
+			_, err = match.PutU32(x)
+			matched += 1
+		} else {
+			_, err = other.PutU32(x)
+			unmatched += 1
+		}
+		if err != nil {
+			return matched, unmatched, err
+		}
+	}
+	if _, err := match.finish(); err != nil {
+		return matched, unmatched, err
+	}
+	_, err = other.finish()
+	return matched, unmatched, err
+}
+
+// countingByteReader counts the bytes read through it
+type countingByteReader struct {
+	r io.ByteReader
+	n int64
+}
+
+func (c *countingByteReader) ReadByte() (byte, error) {
+	x, err := c.r.ReadByte()
+	if err == nil {
+		c.n += 1
+	}
+	return x, err
+}
+
+// CompactBase128 re-encodes the base128 stream src to dst using the shortest encoding of every value,
+// and returns how many bytes smaller the output is. Streams written by a lenient encoder may pad
+// values with continuation bytes (0x81 0x00 for 1), which decode fine but waste space.
+func CompactBase128(dst io.Writer, src io.ByteReader) (shrunk int64, err error) {
+	in := &countingByteReader{r: src}
+	enc := NewU64Base128Encoder(dst)
+	dec := NewU64Base128Decoder(in)
+	var out int64
+	for {
+		x, err := dec.GetU64()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return in.n - out, err
+		}
+		n, err := enc.PutU64(x)
+		out += int64(n)
+		if err != nil {
+			return in.n - out, err
+		}
+	}
+	return in.n - out, nil
+}
+
+// CompactU32GroupVarint re-encodes the group varint stream src to dst with every value stored in
+// as few bytes as it needs, and returns how many bytes smaller the output is.
+// The size byte allows a value to take more bytes than necessary (a zero in four bytes, say),
+// which this undoes.
+func CompactU32GroupVarint(dst io.Writer, src io.ByteReader) (shrunk int64, err error) {
+	in := &countingByteReader{r: src}
+	enc := NewU32GroupVarintEncoder(dst)
+	dec := NewU32GroupVarintDecoder(in)
+	var out int64
+	for {
+		x, err := dec.GetU32()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return in.n - out, err
+		}
+		n, err := enc.PutU32(x)
+		out += int64(n)
+		if err != nil {
+			return in.n - out, err
+		}
+	}
+	n, err := enc.Flush()
+	out += int64(n)
+	return in.n - out, err
+}
+
+// CopyU32 decodes every value from src and writes it to dst until src reports io.EOF,
+// returning how many values were copied. Reaching the end of src isn't an error.
+// Like io.Copy it leaves dst open, so the caller still has to Close it to flush any partial group.
+func CopyU32(dst U32VarintEncoder, src U32VarintDecoder) (n int, err error) {
+	for {
+		x, err := src.GetU32()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if _, err := dst.PutU32(x); err != nil {
+			return n, err
+		}
+		n += 1
+	}
+}
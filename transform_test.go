@@ -0,0 +1,137 @@
+package govarint
+
+import "bytes"
+import "io"
+import "testing"
+
+func TestMapU32(t *testing.T) {
+	data := randomU32s(1000)
+	for i := range data {
+		data[i] >>= 1
+	}
+	var out bytes.Buffer
+	err := MapU32(&out, bytes.NewReader(encodeU32GroupVarint(data)), func(x uint32) uint32 { return x + 1000 })
+	if err != nil {
+		t.Fatalf("MapU32: %s", err)
+	}
+	decoded := decodeU32GroupVarint(t, out.Bytes())
+	if len(decoded) != len(data) {
+		t.Fatalf("%d integers were decoded when %d were encoded", len(decoded), len(data))
+	}
+	for i, x := range data {
+		if decoded[i] != x+1000 {
+			t.Errorf("Got x = %d, expected = %d at %d", decoded[i], x+1000, i)
+		}
+	}
+}
+
+func TestFilterU32(t *testing.T) {
+	var out bytes.Buffer
+	src := bytes.NewReader(encodeU32GroupVarint([]uint32{1, 2, 3, 4, 5, 6}))
+	kept, err := FilterU32(&out, src, func(x uint32) bool { return x%2 == 0 })
+	if kept != 3 || err != nil {
+		t.Fatalf("FilterU32: got kept = %d, err = %v, expected kept = 3", kept, err)
+	}
+	decoded := decodeU32GroupVarint(t, out.Bytes())
+	expected := []uint32{2, 4, 6}
+	if len(decoded) != len(expected) {
+		t.Fatalf("%d integers were decoded when %d were kept", len(decoded), len(expected))
+	}
+	for i := range expected {
+		if decoded[i] != expected[i] {
+			t.Errorf("Got x = %d, expected = %d at %d", decoded[i], expected[i], i)
+		}
+	}
+}
+
+func TestPartitionU32(t *testing.T) {
+	var even, odd bytes.Buffer
+	src := bytes.NewReader(encodeU32GroupVarint([]uint32{1, 2, 3, 4}))
+	matched, unmatched, err := PartitionU32(&even, &odd, src, func(x uint32) bool { return x%2 == 0 })
+	if matched != 2 || unmatched != 2 || err != nil {
+		t.Fatalf("PartitionU32: got matched = %d, unmatched = %d, err = %v, expected 2 and 2", matched, unmatched, err)
+	}
+	for _, part := range []struct {
+		buf      *bytes.Buffer
+		expected []uint32
+	}{{&even, []uint32{2, 4}}, {&odd, []uint32{1, 3}}} {
+		decoded := decodeU32GroupVarint(t, part.buf.Bytes())
+		if len(decoded) != len(part.expected) {
+			t.Fatalf("%d integers were decoded when %d were partitioned", len(decoded), len(part.expected))
+		}
+		for i := range part.expected {
+			if decoded[i] != part.expected[i] {
+				t.Errorf("Got x = %d, expected = %d at %d", decoded[i], part.expected[i], i)
+			}
+		}
+	}
+}
+
+func TestCompactBase128(t *testing.T) {
+	// 1 padded to two bytes, 300 padded to four and 5 already minimal
+	bloated := []byte{0x81, 0x00, 0xac, 0x82, 0x80, 0x00, 0x05}
+	var out bytes.Buffer
+	shrunk, err := CompactBase128(&out, bytes.NewReader(bloated))
+	if shrunk != 3 || err != nil {
+		t.Fatalf("CompactBase128: got shrunk = %d, err = %v, expected shrunk = 3", shrunk, err)
+	}
+	if int64(out.Len()) != int64(len(bloated))-shrunk {
+		t.Fatalf("Output is %d bytes, expected %d", out.Len(), int64(len(bloated))-shrunk)
+	}
+	dec := NewU64Base128Decoder(&out)
+	for _, expected := range []uint64{1, 300, 5} {
+		x, err := dec.GetU64()
+		if err != nil || x != expected {
+			t.Fatalf("Got x = %d, err = %v, expected = %d", x, err, expected)
+		}
+	}
+}
+
+func TestCompactU32GroupVarint(t *testing.T) {
+	// Every value stored in four bytes, followed by a partial group of one
+	bloated := []byte{
+		0xff, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 1, 0x2c, 0, 1, 0, 0,
+		0xc0, 0, 0, 0, 7,
+	}
+	expected := []uint32{1, 0, 300, 65536, 7}
+	var out bytes.Buffer
+	shrunk, err := CompactU32GroupVarint(&out, bytes.NewReader(bloated))
+	if err != nil {
+		t.Fatalf("CompactU32GroupVarint: %s", err)
+	}
+	if shrunk <= 0 || int64(out.Len()) != int64(len(bloated))-shrunk {
+		t.Fatalf("Got shrunk = %d and %d output bytes from %d input bytes", shrunk, out.Len(), len(bloated))
+	}
+	if !bytes.Equal(out.Bytes(), encodeU32GroupVarint(expected)) {
+		t.Errorf("Got %x, expected the canonical %x", out.Bytes(), encodeU32GroupVarint(expected))
+	}
+	decoded := decodeU32GroupVarint(t, out.Bytes())
+	if len(decoded) != len(expected) {
+		t.Fatalf("%d integers were decoded when %d were encoded", len(decoded), len(expected))
+	}
+	for i := range expected {
+		if decoded[i] != expected[i] {
+			t.Errorf("Got x = %d, expected = %d at %d", decoded[i], expected[i], i)
+		}
+	}
+}
+
+func TestCopyU32(t *testing.T) {
+	data := randomU32s(1001)
+	var out bytes.Buffer
+	enc := NewU32Base128Encoder(&out)
+	n, err := CopyU32(enc, NewU32GroupVarintDecoder(bytes.NewReader(encodeU32GroupVarint(data))))
+	enc.Close()
+	if n != len(data) || err != nil {
+		t.Fatalf("CopyU32: got n = %d, err = %v, expected n = %d", n, err, len(data))
+	}
+	dec := NewU32Base128Decoder(&out)
+	for i, expected := range data {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Fatalf("Got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+		}
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected io.EOF after the copied values, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package govarint
+
+import "io"
+
+// VarintGB is group varint with the streams split: the size bytes go to one writer and the value bytes
+// to another, so the tags, which repeat a lot, can be compressed on their own. Each tag byte has the
+// same layout as a group varint size byte, and as there, a partial final group is told apart by the
+// value bytes running out.
+
+type VarintGBEncoder struct {
+	tags, data io.Writer
+	store      [4]uint32
+	index      int
+	buf        [16]byte
+	closed     bool
+}
+
+func NewVarintGBEncoder(tags, data io.Writer) *VarintGBEncoder {
+	return &VarintGBEncoder{tags: tags, data: data}
+}
+
+// flush writes the tag and value bytes of the first index values of the group, returning the total written
+func (b *VarintGBEncoder) flush() (int, error) {
+	tag := [1]byte{}
+	length := 0
+	for i, x := range b.store[:b.index] {
+		size := groupVarintLen(x)
+		for shift := uint(size-1) * 8; ; shift -= 8 {
+			b.buf[length] = byte(x >> shift)
+			length += 1
+			if shift == 0 {
+				break
+			}
+		}
+		tag[0] |= byte(size-1) << (uint(3-i) * 2)
+	}
+	b.index = 0
+	n, err := b.tags.Write(tag[:])
+	if err != nil {
+		return n, err
+	}
+	m, err := b.data.Write(b.buf[:length])
+	return n + m, err
+}
+
+func (b *VarintGBEncoder) PutU32(x uint32) (int, error) {
+	if b.closed {
+		return 0, ErrClosed
+	}
+	b.store[b.index] = x
+	b.index += 1
+	if b.index < 4 {
+		return 0, nil
+	}
+	return b.flush()
+}
+
+// Close writes any partial final group, returning any error from doing so
+func (b *VarintGBEncoder) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	if b.index == 0 {
+		return nil
+	}
+	_, err := b.flush()
+	return err
+}
+
+///
+
+type VarintGBDecoder struct {
+	tags, data io.ByteReader
+	group      [4]uint32
+	pos        int
+	capacity   int
+	finished   bool
+}
+
+func NewVarintGBDecoder(tags, data io.ByteReader) *VarintGBDecoder {
+	return &VarintGBDecoder{tags: tags, data: data}
+}
+
+func (b *VarintGBDecoder) getGroup() error {
+	tag, err := b.tags.ReadByte()
+	if err != nil {
+		b.finished = true
+		return err
+	}
+	b.pos = 0
+	for index, length := range &controlTable[tag] {
+		x := uint32(0)
+		for i := 0; i < int(length); i++ {
+			y, err := b.data.ReadByte()
+			if err == io.EOF && i == 0 && index > 0 {
+				// The value bytes ran out between values, so this is a partial final group
+				b.capacity = index
+				b.finished = true
+				return nil
+			}
+			if err != nil {
+				if err == io.EOF {
+					err = errUnexpectedEOF
+				}
+				b.capacity = 0
+				b.finished = true
+				return err
+			}
+			x = x<<8 | uint32(y)
+		}
+		b.group[index] = x
+	}
+	b.capacity = 4
+	return nil
+}
+
+func (b *VarintGBDecoder) GetU32() (uint32, error) {
+	if b.pos == b.capacity {
+		if b.finished {
+			return 0, io.EOF
+		}
+		if err := b.getGroup(); err != nil {
+			return 0, err
+		}
+	}
+	b.pos += 1
+	return b.group[b.pos-1], nil
+}
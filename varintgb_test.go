@@ -0,0 +1,42 @@
+package govarint
+
+import "bytes"
+import "errors"
+import "io"
+import "testing"
+
+func TestVarintGBRoundTrip(t *testing.T) {
+	for n := 0; n <= len(testU32); n++ {
+		var tags, data bytes.Buffer
+		enc := NewVarintGBEncoder(&tags, &data)
+		for _, x := range testU32[:n] {
+			if _, err := enc.PutU32(x); err != nil {
+				t.Fatalf("PutU32: %s", err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			t.Fatalf("Close: %s", err)
+		}
+		// Together the two streams hold exactly what group varint would
+		if length := tags.Len() + data.Len(); length != GroupVarintEncodedLen(testU32[:n]) {
+			t.Errorf("%d values took %d bytes, expected %d", n, length, GroupVarintEncodedLen(testU32[:n]))
+		}
+		if tags.Len() != (n+3)/4 {
+			t.Errorf("%d values wrote %d tags, expected %d", n, tags.Len(), (n+3)/4)
+		}
+		dec := NewVarintGBDecoder(&tags, &data)
+		for i, expected := range testU32[:n] {
+			if x, err := dec.GetU32(); x != expected || err != nil {
+				t.Errorf("Got x = %d, err = %v, expected = %d at %d", x, err, expected, i)
+			}
+		}
+		if _, err := dec.GetU32(); err != io.EOF {
+			t.Errorf("Expected EOF after %d values, got %v", n, err)
+		}
+	}
+	// A tag promising a two byte value with only one byte left is truncation
+	dec := NewVarintGBDecoder(bytes.NewReader([]byte{0x40}), bytes.NewReader([]byte{1}))
+	if _, err := dec.GetU32(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+}
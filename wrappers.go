@@ -0,0 +1,166 @@
+package govarint
+
+type RateLimitedU32Decoder struct {
+	d         U32VarintDecoder
+	remaining int
+}
+
+// NewRateLimitedU32Decoder bounds how many values can be pulled out of d.
+// Once maxValues have been returned, GetU32 fails with ErrValueLimitExceeded without touching d,
+// even if the stream would have ended there, so nothing past the limit is ever decoded.
+func NewRateLimitedU32Decoder(d U32VarintDecoder, maxValues int) *RateLimitedU32Decoder {
+	return &RateLimitedU32Decoder{d: d, remaining: maxValues}
+}
+
+func (b *RateLimitedU32Decoder) GetU32() (uint32, error) {
+	if b.remaining <= 0 {
+		return 0, ErrValueLimitExceeded
+	}
+	x, err := b.d.GetU32()
+	if err != nil {
+		return x, err
+	}
+	b.remaining -= 1
+	return x, nil
+}
+
+///
+
+type ObservedU32Encoder struct {
+	e       U32VarintEncoder
+	observe func(value uint32, encodedLen int)
+	grouped bool
+	pending []uint32
+}
+
+// NewObservedU32Encoder calls observe with every value written through e and the number of bytes it took.
+// Group varint only knows the sizes once a group is flushed, so for a *U32GroupVarintEncoder the callback
+// fires at flush time (or on Close) for each buffered value, with the group's size byte counted against
+// the first value of the group. With PadOnClose, the padding that completes the last group is counted
+// against the last value. For any other encoder, each value is reported with the length its PutU32
+// returned. That's only right for encoders that write every value as it's put, such as Base128. With other
+// grouping encoders, a group varint encoder wrapped in another (a U32DeltaEncoder, say), or one using
+// WithSyncMarkers, the reported lengths won't add up to the size of the encoded output.
+func NewObservedU32Encoder(e U32VarintEncoder, observe func(value uint32, encodedLen int)) *ObservedU32Encoder {
+	_, grouped := e.(*U32GroupVarintEncoder)
+	return &ObservedU32Encoder{e: e, observe: observe, grouped: grouped}
+}
+
+func (b *ObservedU32Encoder) PutU32(x uint32) (int, error) {
+	n, err := b.e.PutU32(x)
+	if !b.grouped {
+		b.observe(x, n)
+		return n, err
+	}
+	b.pending = append(b.pending, x)
+	if n > 0 {
+		b.flushPending(0)
+	}
+	return n, err
+}
+
+// flushPending reports the values of the group just written, with padding bytes added to the last of them
+func (b *ObservedU32Encoder) flushPending(padding int) {
+	for i, x := range b.pending {
+		length := groupVarintLen(x)
+		if i == 0 {
+			length += 1
+		}
+		if i == len(b.pending)-1 {
+			length += padding
+		}
+		b.observe(x, length)
+	}
+	b.pending = b.pending[:0]
+}
+
+func (b *ObservedU32Encoder) Close() {
+	padding := 0
+	if enc, ok := b.e.(*U32GroupVarintEncoder); ok && enc.PadOnClose && !enc.closed && len(b.pending) > 0 {
+		padding = (4 - len(b.pending)) * groupVarintLen(enc.PadFill)
+	}
+	b.e.Close()
+	b.flushPending(padding)
+}
+
+///
+
+type PeekableU32Decoder struct {
+	d      U32VarintDecoder
+	x      uint32
+	err    error
+	peeked bool
+}
+
+// NewPeekableU32Decoder adds one value of lookahead to any decoder
+func NewPeekableU32Decoder(d U32VarintDecoder) *PeekableU32Decoder {
+	return &PeekableU32Decoder{d: d}
+}
+
+// Peek returns the value the next GetU32 will return without consuming it.
+// An error from the underlying decoder is held the same way, so GetU32 reports it too.
+func (b *PeekableU32Decoder) Peek() (uint32, error) {
+	if !b.peeked {
+		b.x, b.err = b.d.GetU32()
+		b.peeked = true
+	}
+	return b.x, b.err
+}
+
+func (b *PeekableU32Decoder) GetU32() (uint32, error) {
+	if b.peeked {
+		b.peeked = false
+		return b.x, b.err
+	}
+	return b.d.GetU32()
+}
+
+///
+
+type CumSumU32Decoder struct {
+	d   U32VarintDecoder
+	sum uint64
+}
+
+// NewCumSumU32Decoder turns the values of d, such as histogram counts, into running totals
+func NewCumSumU32Decoder(d U32VarintDecoder) *CumSumU32Decoder {
+	return &CumSumU32Decoder{d: d}
+}
+
+// GetU32CumSum returns the sum of every value decoded so far, including the next one.
+// It fails with ErrOverflow if the total no longer fits in 32 bits, leaving the total as it was.
+func (b *CumSumU32Decoder) GetU32CumSum() (uint32, error) {
+	x, err := b.d.GetU32()
+	if err != nil {
+		return 0, err
+	}
+	if b.sum+uint64(x) > 1<<32-1 {
+		return 0, ErrOverflow
+	}
+	b.sum += uint64(x)
+	return uint32(b.sum), nil
+}
+
+///
+
+type RemapU32Decoder struct {
+	d    U32VarintDecoder
+	dict []uint32
+}
+
+// NewRemapU32Decoder decodes a dictionary encoded column, where d yields indices into dict
+func NewRemapU32Decoder(d U32VarintDecoder, dict []uint32) *RemapU32Decoder {
+	return &RemapU32Decoder{d: d, dict: dict}
+}
+
+// GetU32 returns the dictionary entry for the next index, or ErrIndexOutOfRange if dict doesn't reach it
+func (b *RemapU32Decoder) GetU32() (uint32, error) {
+	index, err := b.d.GetU32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(index) >= uint64(len(b.dict)) {
+		return 0, ErrIndexOutOfRange
+	}
+	return b.dict[index], nil
+}
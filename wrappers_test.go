@@ -0,0 +1,165 @@
+package govarint
+
+import "bytes"
+import "io"
+import "testing"
+
+func TestRateLimitedU32Decoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewU32GroupVarintEncoder(&buf)
+	for _, x := range testU32 {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	data := buf.Bytes()
+	dec := NewRateLimitedU32Decoder(NewU32GroupVarintDecoder(bytes.NewReader(data)), 10)
+	for i, expected := range testU32[:10] {
+		x, err := dec.GetU32()
+		if x != expected || err != nil {
+			t.Errorf("GetU32() at %d: got x = %d, expected = %d, err = %s", i, x, expected, err)
+		}
+	}
+	if _, err := dec.GetU32(); err != ErrValueLimitExceeded {
+		t.Errorf("11th GetU32(): got err = %v, expected = %v", err, ErrValueLimitExceeded)
+	}
+	// A stream shorter than the limit still ends cleanly
+	dec = NewRateLimitedU32Decoder(NewU32GroupVarintDecoder(bytes.NewReader(data)), len(testU32)+1)
+	for range testU32 {
+		dec.GetU32()
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("GetU32() at the end of the stream: got err = %v, expected = EOF", err)
+	}
+	// Past the limit the underlying decoder isn't read again
+	counted := &countingU32Decoder{d: NewU32GroupVarintDecoder(bytes.NewReader(data))}
+	dec = NewRateLimitedU32Decoder(counted, 3)
+	for i := 0; i < 6; i++ {
+		dec.GetU32()
+	}
+	if counted.calls != 3 {
+		t.Errorf("The underlying decoder was called %d times for a limit of 3", counted.calls)
+	}
+}
+
+// countingU32Decoder counts the calls made to GetU32
+type countingU32Decoder struct {
+	d     U32VarintDecoder
+	calls int
+}
+
+func (c *countingU32Decoder) GetU32() (uint32, error) {
+	c.calls += 1
+	return c.d.GetU32()
+}
+
+func TestObservedU32Encoder(t *testing.T) {
+	encoders := map[string]func(w io.Writer) U32VarintEncoder{
+		"Base128":     func(w io.Writer) U32VarintEncoder { return NewU32Base128Encoder(w) },
+		"GroupVarint": func(w io.Writer) U32VarintEncoder { return NewU32GroupVarintEncoder(w) },
+		"Padded": func(w io.Writer) U32VarintEncoder {
+			enc := NewU32GroupVarintEncoder(w)
+			enc.PadOnClose = true
+			enc.PadFill = 1 << 20
+			return enc
+		},
+	}
+	for name, newEncoder := range encoders {
+		var buf bytes.Buffer
+		var values []uint32
+		total := 0
+		enc := NewObservedU32Encoder(newEncoder(&buf), func(value uint32, encodedLen int) {
+			values = append(values, value)
+			total += encodedLen
+		})
+		for _, x := range fiveU32 {
+			enc.PutU32(x)
+		}
+		enc.Close()
+		if total != buf.Len() {
+			t.Errorf("%s: observed %d bytes when %d were written", name, total, buf.Len())
+		}
+		if len(values) != len(fiveU32) {
+			t.Fatalf("%s: observed %d values when %d were encoded", name, len(values), len(fiveU32))
+		}
+		for i, expected := range fiveU32 {
+			if values[i] != expected {
+				t.Errorf("%s: observed x = %d, expected = %d at %d", name, values[i], expected, i)
+			}
+		}
+	}
+}
+
+func TestPeekableU32Decoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewU32Base128Encoder(&buf)
+	for _, x := range testU32 {
+		enc.PutU32(x)
+	}
+	enc.Close()
+	decoders := map[string]U32VarintDecoder{
+		"Base128":     NewU32Base128Decoder(bytes.NewReader(buf.Bytes())),
+		"GroupVarint": NewU32GroupVarintDecoder(bytes.NewReader(encodeU32GroupVarint(testU32))),
+	}
+	for name, d := range decoders {
+		dec := NewPeekableU32Decoder(d)
+		for i, expected := range testU32 {
+			// Peek every other value, twice, to check it doesn't consume anything
+			if i%2 == 0 {
+				for j := 0; j < 2; j++ {
+					if x, err := dec.Peek(); x != expected || err != nil {
+						t.Errorf("%s: Peek(): got x = %d, err = %v, expected = %d at %d", name, x, err, expected, i)
+					}
+				}
+			}
+			if x, err := dec.GetU32(); x != expected || err != nil {
+				t.Errorf("%s: GetU32(): got x = %d, err = %v, expected = %d at %d", name, x, err, expected, i)
+			}
+		}
+		if _, err := dec.Peek(); err != io.EOF {
+			t.Errorf("%s: Peek() at the end: got err = %v, expected EOF", name, err)
+		}
+		if _, err := dec.GetU32(); err != io.EOF {
+			t.Errorf("%s: GetU32() at the end: got err = %v, expected EOF", name, err)
+		}
+	}
+}
+
+func TestCumSumU32Decoder(t *testing.T) {
+	dec := NewCumSumU32Decoder(NewU32GroupVarintDecoder(bytes.NewReader(encodeU32GroupVarint([]uint32{1, 2, 3}))))
+	for _, expected := range []uint32{1, 3, 6} {
+		if x, err := dec.GetU32CumSum(); x != expected || err != nil {
+			t.Errorf("GetU32CumSum(): got x = %d, err = %v, expected = %d", x, err, expected)
+		}
+	}
+	if _, err := dec.GetU32CumSum(); err != io.EOF {
+		t.Errorf("Expected EOF after three values, got %v", err)
+	}
+	dec = NewCumSumU32Decoder(NewU32GroupVarintDecoder(bytes.NewReader(encodeU32GroupVarint([]uint32{1<<32 - 2, 1, 1}))))
+	dec.GetU32CumSum()
+	if x, err := dec.GetU32CumSum(); x != 1<<32-1 || err != nil {
+		t.Errorf("Running total of MaxUint32: got x = %d, err = %v", x, err)
+	}
+	if _, err := dec.GetU32CumSum(); err != ErrOverflow {
+		t.Errorf("Running total past MaxUint32: got err = %v, expected ErrOverflow", err)
+	}
+}
+
+func TestRemapU32Decoder(t *testing.T) {
+	dict := []uint32{100, 1 << 31, 7}
+	dec := NewRemapU32Decoder(NewU32GroupVarintDecoder(bytes.NewReader(encodeU32GroupVarint([]uint32{2, 0, 0, 1, 3, 2}))), dict)
+	for _, expected := range []uint32{7, 100, 100, 1 << 31} {
+		if x, err := dec.GetU32(); x != expected || err != nil {
+			t.Errorf("GetU32(): got x = %d, err = %v, expected = %d", x, err, expected)
+		}
+	}
+	if _, err := dec.GetU32(); err != ErrIndexOutOfRange {
+		t.Errorf("Index past the dictionary: got err = %v, expected ErrIndexOutOfRange", err)
+	}
+	// The bad index is skipped over rather than stopping the stream
+	if x, err := dec.GetU32(); x != 7 || err != nil {
+		t.Errorf("GetU32() after a bad index: got x = %d, err = %v, expected = 7", x, err)
+	}
+	if _, err := dec.GetU32(); err != io.EOF {
+		t.Errorf("Expected EOF after six indices, got %v", err)
+	}
+}